@@ -2,6 +2,7 @@ package build
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/goui-org/gouix/certs"
 	"github.com/goui-org/gouix/config"
 	"github.com/goui-org/gouix/files"
 	"github.com/goui-org/gouix/utils"
@@ -19,6 +21,7 @@ import (
 	"github.com/tdewolff/minify/v2/js"
 
 	"github.com/fatih/color"
+	"github.com/skip2/go-qrcode"
 	"github.com/twharmon/gouid"
 )
 
@@ -51,19 +54,30 @@ func (b *Build) ReplaceConfig(cfg *config.Config) {
 
 func (b *Build) BuildDir() string {
 	if os.Getenv("DEBUG") == "true" {
-		return path.Join(os.TempDir(), b.id)
+		return DevBuildDir(b.id)
 	}
 	return "build"
 }
 
+// DevBuildDir returns the directory development builds with the id are
+// written to.
+func DevBuildDir(id string) string {
+	return path.Join(os.TempDir(), id)
+}
+
 func (b *Build) Run() error {
+	return b.RunContext(context.Background())
+}
+
+// RunContext is like Run but stops the compiler when ctx is done.
+func (b *Build) RunContext(ctx context.Context) error {
 	if os.Getenv("DEBUG") == "true" {
-		return b.runDebug()
+		return b.runDebug(ctx)
 	}
-	return b.runProd()
+	return b.runProd(ctx)
 }
 
-func (b *Build) runProd() error {
+func (b *Build) runProd(ctx context.Context) error {
 	fail := func(err error) error {
 		return fmt.Errorf("build.runProd: %w", err)
 	}
@@ -74,7 +88,7 @@ func (b *Build) runProd() error {
 	if err := b.resetOutDir(); err != nil {
 		return fail(err)
 	}
-	out, err := exec.Command("tinygo", "env", "TINYGOROOT").Output()
+	out, err := exec.CommandContext(ctx, "tinygo", "env", "TINYGOROOT").Output()
 	if err != nil {
 		return fail(err)
 	}
@@ -91,7 +105,7 @@ func (b *Build) runProd() error {
 	if err := utils.CopyDirectory("public", outDir, b.minify); err != nil {
 		return fail(err)
 	}
-	if err := b.compile(outDir); err != nil {
+	if err := b.compile(ctx, outDir); err != nil {
 		return fail(err)
 	}
 	dur := time.Since(start).Round(time.Microsecond * 100)
@@ -148,7 +162,7 @@ func (b *Build) resetOutDir() error {
 	return nil
 }
 
-func (b *Build) runDebug() error {
+func (b *Build) runDebug(ctx context.Context) error {
 	fail := func(err error) error {
 		return fmt.Errorf("build.runDebug: %w", err)
 	}
@@ -160,7 +174,7 @@ func (b *Build) runDebug() error {
 		if err := b.resetOutDir(); err != nil {
 			return fail(err)
 		}
-		out, err := exec.Command("tinygo", "env", "TINYGOROOT").Output()
+		out, err := exec.CommandContext(ctx, "tinygo", "env", "TINYGOROOT").Output()
 		if err != nil {
 			return fail(err)
 		}
@@ -169,7 +183,7 @@ func (b *Build) runDebug() error {
 		if err != nil {
 			return fail(err)
 		}
-		bundle := bytes.Join([][]byte{wasmExec, files.DebugJS, files.WasmFetchJS}, []byte("\n"))
+		bundle := bytes.Join([][]byte{wasmExec, files.DebugJS, files.HotJS, files.WasmFetchJS}, []byte("\n"))
 		if err := b.bundleIndexHTML(bundle, outDir); err != nil {
 			return fail(err)
 		}
@@ -178,13 +192,21 @@ func (b *Build) runDebug() error {
 	if err := utils.CopyDirectory("public", outDir, b.minify); err != nil {
 		return fail(err)
 	}
-	if err := b.compile(outDir); err != nil {
+	if err := b.compile(ctx, outDir); err != nil {
 		return fail(err)
 	}
 	dur := time.Since(start).Round(time.Microsecond * 100)
 	utils.ClearTerminal()
 	color.Green("Built successfully in %s!\n\n", dur)
-	fmt.Printf("View in your browser at http://localhost:%d\n\n", b.config.Server.Port)
+	fmt.Printf("View in your browser at %s\n\n", b.config.Server.URL())
+	if b.config.Server.Public() {
+		b.reportNetworkURLs()
+	}
+	if b.config.Server.HTTPS && b.config.Server.Cert == "" {
+		if ca, err := certs.CAPath(); err == nil {
+			fmt.Printf("To avoid certificate warnings, trust the local CA at %s\n\n", ca)
+		}
+	}
 	fmt.Print("To create a build for production, use ")
 	color.Blue("gouix build\n\n")
 	fmt.Printf("Press Ctrl+C to stop\n")
@@ -192,6 +214,31 @@ func (b *Build) runDebug() error {
 	return nil
 }
 
+// CopyPublic copies the public directory into the build directory without
+// compiling.
+func (b *Build) CopyPublic() error {
+	if err := utils.CopyDirectory("public", b.BuildDir(), b.minify); err != nil {
+		return fmt.Errorf("build.CopyPublic: %w", err)
+	}
+	return nil
+}
+
+func (b *Build) reportNetworkURLs() {
+	addrs := utils.LANAddresses()
+	if len(addrs) == 0 {
+		return
+	}
+	for _, addr := range addrs {
+		fmt.Printf("On your network at %s\n", b.config.Server.HostURL(addr))
+	}
+	fmt.Println()
+	qr, err := qrcode.New(b.config.Server.HostURL(addrs[0]), qrcode.Low)
+	if err != nil {
+		return
+	}
+	fmt.Println(qr.ToSmallString(false))
+}
+
 func (b *Build) reportBuildSizes(dir string) error {
 	fail := func(err error) error {
 		return fmt.Errorf("build.reportBuildSizes: %w", err)
@@ -226,39 +273,67 @@ func (b *Build) reportBuildSizes(dir string) error {
 	return nil
 }
 
-func (b *Build) compile(outDir string) error {
-	fmt.Println("compiling src...")
-	src := path.Join("src", "main.go")
+// Commands returns the command lines compile runs to build into outDir,
+// the compiler first and then wasm-opt if it's enabled.
+func (b *Build) Commands(outDir string) [][]string {
+	src := path.Join(b.config.Build.Entry, "main.go")
+	out := path.Join(outDir, "main.wasm")
+	cmds := [][]string{append([]string{b.config.Build.CompilerPath}, CompilerArgs(b.config.Build, src, out)...)}
+	if b.config.Build.WASMOpt {
+		cmds = append(cmds, append([]string{"wasm-opt"}, WASMOptArgs(b.config.Build, out)...))
+	}
+	return cmds
+}
+
+func (b *Build) compile(ctx context.Context, outDir string) error {
+	fmt.Printf("compiling %s...\n", b.config.Build.Entry)
+	src := path.Join(b.config.Build.Entry, "main.go")
 	out := path.Join(outDir, "main.wasm")
 
-	panicOpt := b.config.Build.Panic
-	if b.config.Build.WASMOpt && b.config.Build.NoTraps {
-		panicOpt = "trap"
+	args := CompilerArgs(b.config.Build, src, out)
+	if err := utils.CommandContext(ctx, b.config.Build.CompilerPath, args...); err != nil {
+		return err
 	}
-	parts := []string{
+	if b.config.Build.WASMOpt {
+		return utils.CommandContext(ctx, "wasm-opt", WASMOptArgs(b.config.Build, out)...)
+	}
+	return nil
+}
+
+// PanicMode returns the panic strategy the compiler is run with. no_traps
+// tells wasm-opt that traps never happen, so panics have to trap instead
+// of printing when it's used.
+func PanicMode(cfg *config.BuildConfig) string {
+	if cfg.WASMOpt && cfg.NoTraps {
+		return "trap"
+	}
+	return cfg.Panic
+}
+
+// CompilerArgs returns the arguments the compiler is run with to build src
+// into out.
+func CompilerArgs(cfg *config.BuildConfig, src, out string) []string {
+	args := []string{
 		"build",
 		"-target=wasm",
-		fmt.Sprintf("-gc=%s", b.config.Build.GarbageCollector),
-		fmt.Sprintf("-panic=%s", panicOpt),
-		fmt.Sprintf("-opt=%s", b.config.Build.Opt),
+		fmt.Sprintf("-gc=%s", cfg.GarbageCollector),
+		fmt.Sprintf("-panic=%s", PanicMode(cfg)),
+		fmt.Sprintf("-opt=%s", cfg.Opt),
 		"-o",
 		out,
 	}
-	if !b.config.Build.Debug {
-		parts = append(parts, "-no-debug")
-	}
-	parts = append(parts, src)
-	if err := utils.Command(b.config.Build.CompilerPath, parts...); err != nil {
-		return err
+	if !cfg.Debug {
+		args = append(args, "-no-debug")
 	}
+	return append(args, src)
+}
 
-	if b.config.Build.WASMOpt {
-		parts := []string{"-O4", "-n", "--enable-bulk-memory", "-o", out}
-		if b.config.Build.NoTraps {
-			parts = append(parts, "-tnh")
-		}
-		parts = append(parts, out)
-		return utils.Command("wasm-opt", parts...)
+// WASMOptArgs returns the arguments wasm-opt is run with to optimize out
+// in place.
+func WASMOptArgs(cfg *config.BuildConfig, out string) []string {
+	args := []string{"-O4", "-n", "--enable-bulk-memory", "-o", out}
+	if cfg.NoTraps {
+		args = append(args, "-tnh")
 	}
-	return nil
+	return append(args, out)
 }
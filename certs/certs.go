@@ -0,0 +1,224 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goui-org/gouix/config"
+	"github.com/goui-org/gouix/utils"
+)
+
+const (
+	caValidity   = time.Hour * 24 * 365 * 10
+	certValidity = time.Hour * 24 * 365
+	// renewBefore is how long before expiry a cached certificate is
+	// replaced.
+	renewBefore = time.Hour * 24 * 7
+)
+
+// TLSConfig returns the TLS config of a server configured by cfg, with the
+// certificate in server.cert and server.key, or else one signed by the
+// local CA for the hosts the server is reached at.
+func TLSConfig(cfg *config.ServerConfig) (*tls.Config, error) {
+	var cert tls.Certificate
+	var err error
+	if cfg.Cert != "" || cfg.Key != "" {
+		cert, err = LoadFiles(cfg.Cert, cfg.Key)
+	} else {
+		hosts := []string{cfg.Host}
+		if cfg.Public() {
+			hosts = utils.LANAddresses()
+		}
+		cert, err = Load(hosts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("certs.TLSConfig: %w", err)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
+
+// Dir returns the directory certificates are cached in.
+func Dir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("certs.Dir: %w", err)
+	}
+	return path.Join(dir, "gouix", "certs"), nil
+}
+
+// CAPath returns the path of the local certificate authority, which must be
+// trusted by the browser to avoid certificate warnings.
+func CAPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", fmt.Errorf("certs.CAPath: %w", err)
+	}
+	return path.Join(dir, "ca.pem"), nil
+}
+
+// Load returns a certificate valid for hosts, signed by the local
+// certificate authority. Both are generated on first use and cached.
+func Load(hosts []string) (tls.Certificate, error) {
+	fail := func(err error) (tls.Certificate, error) {
+		return tls.Certificate{}, fmt.Errorf("certs.Load: %w", err)
+	}
+	dir, err := Dir()
+	if err != nil {
+		return fail(err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fail(err)
+	}
+	ca, caKey, err := loadCA(dir)
+	if err != nil {
+		return fail(err)
+	}
+	hosts = normalize(hosts)
+	sum := sha256.Sum256([]byte(strings.Join(hosts, ",")))
+	name := hex.EncodeToString(sum[:8])
+	certFile := path.Join(dir, name+".pem")
+	keyFile := path.Join(dir, name+"-key.pem")
+	if cert, err := loadPair(certFile, keyFile); err == nil && fresh(cert.Leaf) && cert.Leaf.CheckSignatureFrom(ca) == nil {
+		return cert, nil
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fail(err)
+	}
+	tmpl, err := template(hosts[0], certValidity)
+	if err != nil {
+		return fail(err)
+	}
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return fail(err)
+	}
+	if err := write(certFile, keyFile, der, key); err != nil {
+		return fail(err)
+	}
+	cert, err := loadPair(certFile, keyFile)
+	if err != nil {
+		return fail(err)
+	}
+	return cert, nil
+}
+
+func loadPair(certFile, keyFile string) (tls.Certificate, error) {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return pair, err
+	}
+	if pair.Leaf == nil {
+		pair.Leaf, err = x509.ParseCertificate(pair.Certificate[0])
+	}
+	return pair, err
+}
+
+// LoadFiles loads a user supplied certificate and key.
+func LoadFiles(certFile, keyFile string) (tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("certs.LoadFiles: %w", err)
+	}
+	return cert, nil
+}
+
+func loadCA(dir string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certFile := path.Join(dir, "ca.pem")
+	keyFile := path.Join(dir, "ca-key.pem")
+	if pair, err := loadPair(certFile, keyFile); err == nil && fresh(pair.Leaf) {
+		if key, ok := pair.PrivateKey.(*ecdsa.PrivateKey); ok {
+			return pair.Leaf, key, nil
+		}
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl, err := template("gouix development CA", caValidity)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl.IsCA = true
+	tmpl.BasicConstraintsValid = true
+	tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := write(certFile, keyFile, der, key); err != nil {
+		return nil, nil, err
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ca, key, nil
+}
+
+func template(name string, validity time.Duration) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name, Organization: []string{"gouix"}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(validity),
+	}, nil
+}
+
+func write(certFile, keyFile string, der []byte, key *ecdsa.PrivateKey) error {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return err
+	}
+	return utils.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func fresh(cert *x509.Certificate) bool {
+	return cert != nil && time.Now().Add(renewBefore).Before(cert.NotAfter)
+}
+
+func normalize(hosts []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, h := range append([]string{"localhost", "127.0.0.1", "::1"}, hosts...) {
+		if h == "" || seen[h] {
+			continue
+		}
+		seen[h] = true
+		out = append(out, h)
+	}
+	sort.Strings(out[3:])
+	return out
+}
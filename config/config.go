@@ -1,19 +1,134 @@
 package config
 
 import (
+	"fmt"
 	"io"
 	"log"
+	"net"
 	"os"
+	"strconv"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type ServerConfig struct {
-	Port  int    `yaml:"port"`
-	Proxy string `yaml:"proxy"`
+	// Host is the interface to listen on. Use 0.0.0.0 to reach the server
+	// from other devices on the network
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+	// StrictPort fails instead of trying the next free port when Port is
+	// taken
+	StrictPort bool   `yaml:"strict_port"`
+	Proxy      string `yaml:"proxy"`
+	// HMR swaps in rebuilt modules without reloading the page, keeping
+	// state the app saves with hotSave
+	HMR   bool         `yaml:"hmr"`
+	Watch *WatchConfig `yaml:"watch"`
+	// HTTPS serves over TLS with a certificate signed by a local CA, unless
+	// Cert and Key point to one
+	HTTPS bool   `yaml:"https"`
+	Cert  string `yaml:"cert"`
+	Key   string `yaml:"key"`
+	// Headers are added to every response
+	Headers map[string]string `yaml:"headers"`
+	// PathHeaders are added to responses for paths matching a glob
+	PathHeaders []*PathHeaders `yaml:"path_headers"`
+	// CrossOriginIsolated sets the COOP and COEP headers needed for
+	// SharedArrayBuffer and threads
+	CrossOriginIsolated bool        `yaml:"cross_origin_isolated"`
+	CORS                *CORSConfig `yaml:"cors"`
+	// AccessLog prints every request served
+	AccessLog bool `yaml:"access_log"`
+	// Mocks answer API requests when serving with --mock, in addition to
+	// the responses in the mocks directory
+	Mocks []*MockConfig `yaml:"mocks"`
+}
+
+type MockConfig struct {
+	// Method is an HTTP method, or ANY
+	Method string `yaml:"method"`
+	// Path may contain :name parameters and end with a * wildcard
+	Path    string            `yaml:"path"`
+	Status  int               `yaml:"status"`
+	Delay   time.Duration     `yaml:"delay"`
+	Headers map[string]string `yaml:"headers"`
+	// File is the path of the response body relative to the mocks
+	// directory. Files ending in .tmpl are templates
+	File string `yaml:"file"`
+	Body string `yaml:"body"`
+	// Template renders Body as a Go template
+	Template bool `yaml:"template"`
+}
+
+type PathHeaders struct {
+	Path    string            `yaml:"path"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+type CORSConfig struct {
+	// Origins allowed to make cross origin requests, or "*" for any
+	Origins       []string `yaml:"origins"`
+	Methods       []string `yaml:"methods"`
+	Headers       []string `yaml:"headers"`
+	ExposeHeaders []string `yaml:"expose_headers"`
+	Credentials   bool     `yaml:"credentials"`
+	// MaxAge is how many seconds browsers may cache preflight responses
+	MaxAge int `yaml:"max_age"`
+}
+
+// Scheme returns the URL scheme the development server is reached with.
+func (s *ServerConfig) Scheme() string {
+	if s.HTTPS {
+		return "https"
+	}
+	return "http"
+}
+
+// Addr returns the address the development server listens on.
+func (s *ServerConfig) Addr() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+// Public reports whether the server listens on all interfaces.
+func (s *ServerConfig) Public() bool {
+	ip := net.ParseIP(s.Host)
+	return ip != nil && ip.IsUnspecified()
+}
+
+// URL returns the address to open the development server at.
+func (s *ServerConfig) URL() string {
+	host := s.Host
+	if s.Public() {
+		host = "localhost"
+	}
+	return s.HostURL(host)
+}
+
+// HostURL returns the address of the development server on host.
+func (s *ServerConfig) HostURL(host string) string {
+	return fmt.Sprintf("%s://%s", s.Scheme(), net.JoinHostPort(host, strconv.Itoa(s.Port)))
+}
+
+// MinPollInterval is the shortest server.watch.interval, so that polling
+// doesn't scan the project nonstop.
+const MinPollInterval = time.Millisecond * 50
+
+type WatchConfig struct {
+	// Ignore lists gitignore style glob patterns for paths that shouldn't
+	// trigger a rebuild, in addition to those in .gitignore
+	Ignore []string `yaml:"ignore"`
+	// Mode is auto, native or poll. Auto uses filesystem notifications and
+	// falls back to polling when the system runs out of watches
+	Mode string `yaml:"mode"`
+	// Interval is how often files are scanned in poll mode, a duration with
+	// a unit such as 500ms. It can't be less than 50ms
+	Interval time.Duration `yaml:"interval"`
 }
 
 type BuildConfig struct {
+	// Entry is the directory of the main package
+	Entry   string `yaml:"entry"`
 	Panic   string `yaml:"panic"`
 	Debug   bool   `yaml:"debug"`
 	Opt     string `yaml:"opt"`
@@ -21,12 +136,14 @@ type BuildConfig struct {
 	// NoTraps tells wasm-opt that traps never happen
 	NoTraps          bool   `yaml:"no_traps"`
 	CompilerPath     string `yaml:"compiler_path"`
-	GarbageCollector string `yaml:"garbage_collector"`
+	GarbageCollector string `yaml:"gc"`
 }
 
 type Config struct {
-	Server *ServerConfig `yaml:"server"`
-	Build  *BuildConfig  `yaml:"build"`
+	// Version is the format version of the file, see Migrate
+	Version int           `yaml:"version"`
+	Server  *ServerConfig `yaml:"server"`
+	Build   *BuildConfig  `yaml:"build"`
 }
 
 func Get() *Config {
@@ -42,12 +159,40 @@ func Get() *Config {
 	if err := yaml.Unmarshal(b, &cfg); err != nil {
 		log.Fatalln(err)
 	}
+	cfg.setDefaults()
+	return &cfg
+}
+
+// setDefaults fills in the settings goui.yml leaves out.
+func (cfg *Config) setDefaults() {
+	if cfg.Server == nil {
+		cfg.Server = &ServerConfig{}
+	}
+	if cfg.Build == nil {
+		cfg.Build = &BuildConfig{}
+	}
+	if cfg.Build.Entry == "" {
+		cfg.Build.Entry = "src"
+	}
 	if cfg.Build.Panic == "" {
 		cfg.Build.Panic = "print"
 	}
 	if cfg.Build.Opt == "" {
 		cfg.Build.Opt = "2"
 	}
+	if cfg.Server.Watch == nil {
+		cfg.Server.Watch = &WatchConfig{}
+	}
+	if cfg.Server.Watch.Mode == "" {
+		cfg.Server.Watch.Mode = "auto"
+	}
+	if cfg.Server.Watch.Interval <= 0 {
+		cfg.Server.Watch.Interval = time.Millisecond * 500
+	}
+	cfg.Server.Watch.Interval = max(cfg.Server.Watch.Interval, MinPollInterval)
+	if cfg.Server.Host == "" {
+		cfg.Server.Host = "localhost"
+	}
 	if cfg.Server.Port == 0 {
 		cfg.Server.Port = 3000
 	}
@@ -57,5 +202,4 @@ func Get() *Config {
 	if cfg.Build.GarbageCollector == "" {
 		cfg.Build.GarbageCollector = "conservative"
 	}
-	return &cfg
 }
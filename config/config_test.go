@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestPollInterval(t *testing.T) {
+	tests := []struct {
+		src  string
+		want time.Duration
+	}{
+		{"server:\n  port: 3000\n", 500 * time.Millisecond},
+		{"server:\n  watch:\n    interval: 2s\n", 2 * time.Second},
+		{"server:\n  watch:\n    interval: 0s\n", 500 * time.Millisecond},
+		{"server:\n  watch:\n    interval: -1s\n", 500 * time.Millisecond},
+		{"server:\n  watch:\n    interval: 1ns\n", MinPollInterval},
+		{"server:\n  watch:\n    interval: 10ms\n", MinPollInterval},
+	}
+	for _, tt := range tests {
+		var cfg Config
+		if err := yaml.Unmarshal([]byte(tt.src), &cfg); err != nil {
+			t.Fatal(err)
+		}
+		cfg.setDefaults()
+		if got := cfg.Server.Watch.Interval; got != tt.want {
+			t.Errorf("%q: interval = %s, want %s", tt.src, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// migration changes goui.yml from the previous format version to version.
+type migration struct {
+	version int
+	changes []*keyChange
+}
+
+// keyChange renames or removes a key.
+type keyChange struct {
+	// key is the dotted path of the key, such as build.opt
+	key string
+	// to is the new name of the key, in the same mapping. The key is
+	// removed when it's empty.
+	to string
+}
+
+func (c *keyChange) String() string {
+	if c.to == "" {
+		return c.key + " was removed"
+	}
+	return fmt.Sprintf("%s is now %s", c.key, c.newKey())
+}
+
+// newKey returns the dotted path of the renamed key.
+func (c *keyChange) newKey() string {
+	if i := strings.LastIndex(c.key, "."); i >= 0 {
+		return c.key[:i+1] + c.to
+	}
+	return c.to
+}
+
+// migrations are applied in order to files older than their version. Add
+// one whenever a key is renamed or removed, and bump the version in the
+// goui.yml template. Files without a version are version 1.
+var migrations = []*migration{
+	{
+		version: 2,
+		changes: []*keyChange{
+			// named after tinygo's -gc flag, like opt and panic
+			{key: "build.garbage_collector", to: "gc"},
+		},
+	},
+}
+
+// CurrentVersion is the format version written by this gouix.
+func CurrentVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// Migrate applies the migrations newer than the version of the goui.yml
+// in b. The file is edited in place, so everything the migrations don't
+// touch keeps its layout, quoting and comments. It returns the migrated
+// file and what changed, which is empty if no migration applied to it, in
+// which case the file is returned as is.
+func Migrate(b []byte) ([]byte, []string, error) {
+	fail := func(err error) ([]byte, []string, error) {
+		return nil, nil, fmt.Errorf("config.Migrate: %w", err)
+	}
+	d, err := parseDocument(b)
+	if err != nil {
+		return fail(err)
+	}
+	version := 1
+	if v := d.node("version"); v != nil {
+		n, err := strconv.Atoi(v.Value)
+		if err != nil {
+			return fail(fmt.Errorf("invalid version %q", v.Value))
+		}
+		version = n
+	}
+	if version > CurrentVersion() {
+		return fail(fmt.Errorf("goui.yml is version %d, this gouix only knows up to %d", version, CurrentVersion()))
+	}
+	var applied []string
+	for _, m := range migrations {
+		if m.version <= version {
+			continue
+		}
+		for _, c := range m.changes {
+			changed, err := d.apply(c)
+			if err != nil {
+				return fail(err)
+			}
+			if changed {
+				applied = append(applied, fmt.Sprintf("v%d: %s", m.version, c))
+			}
+		}
+	}
+	if len(applied) == 0 {
+		return b, nil, nil
+	}
+	if err := d.setVersion(CurrentVersion()); err != nil {
+		return fail(err)
+	}
+	return d.bytes(), applied, nil
+}
+
+// lookup returns the value of key in a mapping node.
+func lookup(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// keyChangeOf returns the change that renamed or removed the key at the
+// dotted path, if any.
+func keyChangeOf(key string) *keyChange {
+	for _, m := range migrations {
+		for _, c := range m.changes {
+			if c.key == key {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// document is goui.yml being migrated. Edits change its lines and parse
+// it again, so the nodes always match the text.
+type document struct {
+	lines []string
+	root  *yaml.Node
+}
+
+func parseDocument(b []byte) (*document, error) {
+	d := &document{lines: strings.SplitAfter(string(b), "\n")}
+	if err := d.parse(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *document) parse() error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(d.bytes(), &doc); err != nil {
+		return err
+	}
+	d.root = nil
+	if len(doc.Content) > 0 {
+		d.root = doc.Content[0]
+		if d.root.Kind != yaml.MappingNode {
+			return fmt.Errorf("goui.yml must be a mapping")
+		}
+	}
+	return nil
+}
+
+func (d *document) bytes() []byte {
+	return []byte(strings.Join(d.lines, ""))
+}
+
+// node returns the value at the dotted path, or nil if there is none.
+func (d *document) node(path string) *yaml.Node {
+	key, value := d.pair(path)
+	if key == nil {
+		return nil
+	}
+	return value
+}
+
+// pair returns the key and value nodes at the dotted path, and nil if
+// there is no such key.
+func (d *document) pair(path string) (*yaml.Node, *yaml.Node) {
+	m := d.root
+	names := strings.Split(path, ".")
+	for _, name := range names[:len(names)-1] {
+		if m == nil || m.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+		m = lookup(m, name)
+	}
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	key := names[len(names)-1]
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i], m.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// parent returns the mapping holding the key at the dotted path.
+func (d *document) parent(path string) *yaml.Node {
+	i := strings.LastIndex(path, ".")
+	if i < 0 {
+		return d.root
+	}
+	return d.node(path[:i])
+}
+
+// apply makes the change, reporting whether the file has the key.
+func (d *document) apply(c *keyChange) (bool, error) {
+	key, value := d.pair(c.key)
+	if key == nil {
+		return false, nil
+	}
+	if c.to == "" {
+		if m := d.parent(c.key); m.Style&yaml.FlowStyle != 0 {
+			return false, fmt.Errorf("line %d: can't remove %s from a flow mapping, edit it by hand", key.Line, c.key)
+		}
+		return true, d.remove(key, value)
+	}
+	if k, _ := d.pair(c.newKey()); k != nil {
+		return false, fmt.Errorf("line %d: %s and %s are both set, remove one of them", key.Line, c.key, c.newKey())
+	}
+	return true, d.replace(key, c.to)
+}
+
+// replace replaces the text of the scalar n with value, quoted the same
+// way.
+func (d *document) replace(n *yaml.Node, value string) error {
+	old, err := quote(n, n.Value)
+	if err != nil {
+		return err
+	}
+	text, err := quote(n, value)
+	if err != nil {
+		return err
+	}
+	line := []rune(d.lines[n.Line-1])
+	start := n.Column - 1
+	if start+len([]rune(old)) > len(line) || string(line[start:start+len([]rune(old))]) != old {
+		return fmt.Errorf("line %d: can't find %s, edit it by hand", n.Line, old)
+	}
+	d.lines[n.Line-1] = string(line[:start]) + text + string(line[start+len([]rune(old)):])
+	return d.parse()
+}
+
+// quote returns value written in the style of the scalar n.
+func quote(n *yaml.Node, value string) (string, error) {
+	switch n.Style {
+	case 0:
+		return value, nil
+	case yaml.DoubleQuotedStyle:
+		return strconv.Quote(value), nil
+	case yaml.SingleQuotedStyle:
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'", nil
+	}
+	return "", fmt.Errorf("line %d: can't edit %q, edit it by hand", n.Line, n.Value)
+}
+
+// remove deletes the lines of a key and its value in a block mapping.
+// Comments and blank lines after them are kept, as they likely belong to
+// what follows.
+func (d *document) remove(key, value *yaml.Node) error {
+	if strings.TrimSpace(string([]rune(d.lines[key.Line-1])[:key.Column-1])) != "" {
+		return fmt.Errorf("line %d: can't remove %s, edit it by hand", key.Line, key.Value)
+	}
+	indent := key.Column - 1
+	end := key.Line
+	for i := key.Line; i < len(d.lines); i++ {
+		text := strings.TrimRight(d.lines[i], "\r\n")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		// a block sequence can start at the indentation of its key
+		n := len(text) - len(trimmed)
+		if n > indent || n == indent && value.Kind == yaml.SequenceNode && strings.HasPrefix(trimmed, "-") {
+			end = i + 1
+			continue
+		}
+		break
+	}
+	d.lines = append(d.lines[:key.Line-1], d.lines[end:]...)
+	return d.parse()
+}
+
+// setVersion sets the version key, adding it before the first key if
+// missing.
+func (d *document) setVersion(version int) error {
+	v := strconv.Itoa(version)
+	if n := d.node("version"); n != nil {
+		return d.replace(n, v)
+	}
+	if d.root == nil || len(d.root.Content) == 0 {
+		if n := len(d.lines); n > 0 && d.lines[n-1] != "" && !strings.HasSuffix(d.lines[n-1], "\n") {
+			d.lines[n-1] += "\n"
+		}
+		d.lines = append(d.lines, "version: "+v+"\n")
+		return d.parse()
+	}
+	if d.root.Style&yaml.FlowStyle != 0 {
+		return fmt.Errorf("can't add the version to a flow mapping, edit it by hand")
+	}
+	first := d.root.Content[0]
+	line := strings.Repeat(" ", first.Column-1) + "version: " + v + "\n"
+	d.lines = append(d.lines[:first.Line-1], append([]string{line}, d.lines[first.Line-1:]...)...)
+	return d.parse()
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		applied []string
+		wantErr string
+	}{
+		{
+			name: "rename",
+			in: "# yaml-language-server: $schema=.gouix/goui.schema.json\n" +
+				"server:\n" +
+				"    port: 3000   # odd indent\n" +
+				"build:\n" +
+				"    opt: 'z'\n" +
+				"    garbage_collector: leaking # for now\n",
+			want: "# yaml-language-server: $schema=.gouix/goui.schema.json\n" +
+				"version: 2\n" +
+				"server:\n" +
+				"    port: 3000   # odd indent\n" +
+				"build:\n" +
+				"    opt: 'z'\n" +
+				"    gc: leaking # for now\n",
+			applied: []string{"v2: build.garbage_collector is now build.gc"},
+		},
+		{
+			name:    "quoted key and existing version",
+			in:      "version: 1\nbuild: {\"garbage_collector\": precise}\n",
+			want:    "version: 2\nbuild: {\"gc\": precise}\n",
+			applied: []string{"v2: build.garbage_collector is now build.gc"},
+		},
+		{
+			name: "nothing to migrate",
+			in:   "build:\n  opt: z\n",
+			want: "build:\n  opt: z\n",
+		},
+		{
+			name: "current",
+			in:   "version: 2\nbuild:\n  garbage_collector: leaking\n",
+			want: "version: 2\nbuild:\n  garbage_collector: leaking\n",
+		},
+		{
+			name: "empty",
+			in:   "",
+			want: "",
+		},
+		{
+			name:    "both keys",
+			in:      "build:\n  garbage_collector: leaking\n  gc: precise\n",
+			wantErr: "both set",
+		},
+		{
+			name:    "newer",
+			in:      "version: 3\n",
+			wantErr: "only knows up to 2",
+		},
+		{
+			name:    "not a mapping",
+			in:      "- build\n",
+			wantErr: "must be a mapping",
+		},
+	}
+	for _, tt := range tests {
+		got, applied, err := Migrate([]byte(tt.in))
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: Migrate() error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Migrate() error = %v", tt.name, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: Migrate() =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+		if !reflect.DeepEqual(applied, tt.applied) {
+			t.Errorf("%s: applied %q, want %q", tt.name, applied, tt.applied)
+		}
+	}
+}
+
+func TestMigrateRemove(t *testing.T) {
+	saved := migrations
+	t.Cleanup(func() { migrations = saved })
+	migrations = []*migration{{
+		version: 2,
+		changes: []*keyChange{
+			{key: "server.watch"},
+			{key: "server.mocks"},
+			{key: "build.notes"},
+		},
+	}}
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "nested mapping",
+			in:   "server:\n  watch:\n    mode: poll\n\n    interval: 1s\n  # the port\n  port: 3000\n",
+			want: "version: 2\nserver:\n  # the port\n  port: 3000\n",
+		},
+		{
+			name: "sequence at the key's indentation",
+			in:   "server:\n  mocks:\n  - path: /a\n    body: a\n  - path: /b\n  port: 3000\n",
+			want: "version: 2\nserver:\n  port: 3000\n",
+		},
+		{
+			name: "block scalar",
+			in:   "build:\n  notes: |\n    # not a comment\n\n    more\n\n# build settings end\nserver:\n  port: 3000\n",
+			want: "version: 2\nbuild:\n\n# build settings end\nserver:\n  port: 3000\n",
+		},
+		{
+			name: "last key",
+			in:   "server:\n  watch:\n    mode: poll",
+			want: "version: 2\nserver:\n",
+		},
+		{
+			name:    "flow mapping",
+			in:      "server: {port: 3000,\n  watch: {mode: poll}}\n",
+			wantErr: "flow mapping",
+		},
+	}
+	for _, tt := range tests {
+		got, _, err := Migrate([]byte(tt.in))
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: Migrate() error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Migrate() error = %v", tt.name, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: Migrate() =\n%q\nwant\n%q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateMigratedKey(t *testing.T) {
+	problems, err := Validate([]byte("build:\n  garbage_collector: leaking\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 || problems[0].Message != "build.garbage_collector is now build.gc, gouix upgrade migrates it" {
+		t.Errorf("Validate() = %v", problems)
+	}
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sources of setting values. Resolve only knows the first two; commands
+// report the flags and environment variables that override settings.
+const (
+	SourceDefault = "default"
+	SourceFile    = "goui.yml"
+	SourceFlag    = "flag"
+	SourceEnv     = "env"
+)
+
+// Setting is a resolved config value and where it came from.
+type Setting struct {
+	// Key is the dotted path of the setting, such as build.opt
+	Key    string
+	Value  string
+	Source string
+}
+
+// Resolve parses the goui.yml in b, fills in the defaults and returns the
+// config along with every setting in it, in the order of the fields.
+func Resolve(b []byte) (*Config, []*Setting, error) {
+	fail := func(err error) (*Config, []*Setting, error) {
+		return nil, nil, fmt.Errorf("config.Resolve: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return fail(err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return fail(err)
+	}
+	cfg.setDefaults()
+	var root *yaml.Node
+	if len(doc.Content) > 0 {
+		root = doc.Content[0]
+	}
+	return &cfg, settings(reflect.ValueOf(cfg), root, "", nil), nil
+}
+
+// settings appends the settings of the struct v, whose node in goui.yml
+// is n, or nil if the file leaves it out.
+func settings(v reflect.Value, n *yaml.Node, prefix string, out []*Setting) []*Setting {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		field := v.Field(i)
+		var child *yaml.Node
+		if n != nil && n.Kind == yaml.MappingNode {
+			child = lookup(n, name)
+		}
+		if child != nil && child.Kind == yaml.AliasNode {
+			child = child.Alias
+		}
+		if field.Kind() == reflect.Pointer && field.Type().Elem().Kind() == reflect.Struct {
+			if field.IsNil() {
+				field = reflect.New(field.Type().Elem())
+			}
+			out = settings(field.Elem(), child, prefix+name+".", out)
+			continue
+		}
+		source := SourceDefault
+		if child != nil && child.Tag != "!!null" {
+			source = SourceFile
+		}
+		out = append(out, &Setting{Key: prefix + name, Value: format(field), Source: source})
+	}
+	return out
+}
+
+// format returns v the way it's written in goui.yml, with lists and maps
+// on one line.
+func format(v reflect.Value) string {
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		if v.String() == "" {
+			return `""`
+		}
+		return v.String()
+	case reflect.Slice, reflect.Map:
+		if v.Len() == 0 {
+			if v.Kind() == reflect.Map {
+				return "{}"
+			}
+			return "[]"
+		}
+		var n yaml.Node
+		if err := n.Encode(v.Interface()); err != nil {
+			return fmt.Sprint(v.Interface())
+		}
+		flow(&n)
+		b, err := yaml.Marshal(&n)
+		if err != nil {
+			return fmt.Sprint(v.Interface())
+		}
+		return strings.TrimSpace(string(b))
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// flow sets n and its children to the flow style.
+func flow(n *yaml.Node) {
+	n.Style |= yaml.FlowStyle
+	for _, c := range n.Content {
+		flow(c)
+	}
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// source is parsed for the doc comments of the config fields, which
+// become the descriptions in the schema.
+//
+//go:embed config.go
+var source string
+
+// durationPattern matches the durations time.ParseDuration accepts.
+const durationPattern = `^-?([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+$`
+
+// enums lists the allowed values of fields, by type and field name.
+var enums = map[string][]any{
+	"WatchConfig.Mode":             {"auto", "native", "poll"},
+	"MockConfig.Method":            {"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "ANY"},
+	"BuildConfig.Panic":            {"print", "trap"},
+	"BuildConfig.Opt":              {"0", "1", "2", "s", "z", 0, 1, 2},
+	"BuildConfig.GarbageCollector": {"conservative", "precise", "leaking", "none", "custom"},
+}
+
+// minDurations lists the smallest values of duration fields, by type and
+// field name.
+var minDurations = map[string]time.Duration{
+	"WatchConfig.Interval": MinPollInterval,
+}
+
+// Schema is a JSON Schema, limited to what describing goui.yml needs.
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        any                `json:"type,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	// AdditionalProperties is false for structs and the schema of the
+	// values for maps
+	AdditionalProperties any     `json:"additionalProperties,omitempty"`
+	Items                *Schema `json:"items,omitempty"`
+	Enum                 []any   `json:"enum,omitempty"`
+	Pattern              string  `json:"pattern,omitempty"`
+	Default              any     `json:"default,omitempty"`
+	// minDuration is checked by Validate, JSON Schema can't express it
+	minDuration time.Duration
+}
+
+// JSONSchema returns the JSON Schema of goui.yml, derived from Config.
+func JSONSchema() *Schema {
+	defaults := &Config{}
+	defaults.setDefaults()
+	s := schemaOf(reflect.TypeOf(Config{}), reflect.ValueOf(*defaults), fieldDocs())
+	s.Schema = "http://json-schema.org/draft-07/schema#"
+	s.Title = "goui.yml"
+	return s
+}
+
+// JSONSchemaBytes returns the indented JSON of JSONSchema.
+func JSONSchemaBytes() []byte {
+	b, err := json.MarshalIndent(JSONSchema(), "", "    ")
+	if err != nil {
+		panic(fmt.Sprintf("config.JSONSchemaBytes: %s", err))
+	}
+	return append(b, '\n')
+}
+
+// schemaOf returns the schema of t, whose default value is def.
+func schemaOf(t reflect.Type, def reflect.Value, docs map[string]string) *Schema {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		s := &Schema{Type: "string", Pattern: durationPattern}
+		if def.IsValid() && !def.IsZero() {
+			s.Default = time.Duration(def.Int()).String()
+		}
+		return s
+	}
+	if t.Kind() == reflect.Pointer {
+		if def.IsValid() && !def.IsNil() {
+			def = def.Elem()
+		} else {
+			def = reflect.Value{}
+		}
+		return schemaOf(t.Elem(), def, docs)
+	}
+	s := &Schema{}
+	switch t.Kind() {
+	case reflect.String:
+		s.Type = "string"
+	case reflect.Bool:
+		s.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s.Type = "integer"
+	case reflect.Float32, reflect.Float64:
+		s.Type = "number"
+	case reflect.Slice:
+		s.Type = "array"
+		s.Items = schemaOf(t.Elem(), reflect.Value{}, docs)
+	case reflect.Map:
+		s.Type = "object"
+		s.AdditionalProperties = schemaOf(t.Elem(), reflect.Value{}, docs)
+	case reflect.Struct:
+		s.Type = "object"
+		s.AdditionalProperties = false
+		s.Properties = make(map[string]*Schema)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(f.Name)
+			}
+			var fieldDef reflect.Value
+			if def.IsValid() {
+				fieldDef = def.Field(i)
+			}
+			fs := schemaOf(f.Type, fieldDef, docs)
+			key := t.Name() + "." + f.Name
+			fs.Description = docs[key]
+			fs.minDuration = minDurations[key]
+			if enum, ok := enums[key]; ok {
+				fs.Type = nil
+				fs.Enum = enum
+			}
+			s.Properties[name] = fs
+		}
+		return s
+	}
+	if def.IsValid() && !def.IsZero() {
+		s.Default = def.Interface()
+	}
+	return s
+}
+
+// fieldDocs returns the doc comments of the struct fields in source, by
+// type and field name, joined into one line.
+func fieldDocs() map[string]string {
+	docs := make(map[string]string)
+	f, err := parser.ParseFile(token.NewFileSet(), "config.go", source, parser.ParseComments)
+	if err != nil {
+		return docs
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		ts, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		st, ok := ts.Type.(*ast.StructType)
+		if !ok {
+			return false
+		}
+		for _, field := range st.Fields.List {
+			if field.Doc == nil {
+				continue
+			}
+			doc := strings.Join(strings.Fields(field.Doc.Text()), " ")
+			for _, name := range field.Names {
+				docs[ts.Name.Name+"."+name.Name] = doc
+			}
+		}
+		return false
+	})
+	return docs
+}
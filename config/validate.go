@@ -0,0 +1,204 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is a place where goui.yml doesn't match the schema.
+type Problem struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (p *Problem) String() string {
+	return fmt.Sprintf("%d:%d: %s", p.Line, p.Column, p.Message)
+}
+
+// Validate checks the goui.yml in b against JSONSchema, returning the
+// problems found in the order they appear in the file.
+func Validate(b []byte) ([]*Problem, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("config.Validate: %w", err)
+	}
+	var problems []*Problem
+	if len(doc.Content) > 0 {
+		problems = validateNode(doc.Content[0], JSONSchema(), "", problems)
+	}
+	if len(problems) == 0 {
+		// catch what the schema can't express, such as integers that
+		// overflow
+		var cfg Config
+		if err := yaml.Unmarshal(b, &cfg); err != nil {
+			problems = append(problems, &Problem{Line: 1, Column: 1, Message: err.Error()})
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+	return problems, nil
+}
+
+func validateNode(n *yaml.Node, s *Schema, path string, problems []*Problem) []*Problem {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	problem := func(format string, a ...any) []*Problem {
+		msg := fmt.Sprintf(format, a...)
+		if path != "" {
+			msg = path + ": " + msg
+		}
+		return append(problems, &Problem{Line: n.Line, Column: n.Column, Message: msg})
+	}
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!null" {
+		return problems
+	}
+	if len(s.Enum) > 0 {
+		if n.Kind == yaml.ScalarNode {
+			for _, v := range s.Enum {
+				if fmt.Sprint(v) == n.Value {
+					return problems
+				}
+			}
+		}
+		values := make([]string, 0, len(s.Enum))
+		seen := make(map[string]bool)
+		for _, v := range s.Enum {
+			if str := fmt.Sprint(v); !seen[str] {
+				seen[str] = true
+				values = append(values, str)
+			}
+		}
+		return problem("must be one of %s", strings.Join(values, ", "))
+	}
+	types := schemaTypes(s)
+	switch {
+	case types["object"] && n.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			keyPath := key.Value
+			if path != "" {
+				keyPath = path + "." + key.Value
+			}
+			if prop, ok := s.Properties[key.Value]; ok {
+				problems = validateNode(value, prop, keyPath, problems)
+				continue
+			}
+			switch extra := s.AdditionalProperties.(type) {
+			case *Schema:
+				problems = validateNode(value, extra, keyPath, problems)
+			case bool:
+				if !extra {
+					problems = append(problems, &Problem{Line: key.Line, Column: key.Column, Message: unknownKey(path, key.Value, s)})
+				}
+			}
+		}
+		return problems
+	case types["array"] && n.Kind == yaml.SequenceNode:
+		for i, item := range n.Content {
+			problems = validateNode(item, s.Items, fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+		return problems
+	case n.Kind == yaml.ScalarNode:
+		if s.Pattern == durationPattern && n.Tag == "!!int" {
+			return problem("%s needs a unit, such as %sms", n.Value, n.Value)
+		}
+		if d, err := time.ParseDuration(n.Value); err == nil && s.minDuration > 0 && d < s.minDuration {
+			return problem("must be at least %s", s.minDuration)
+		}
+		switch {
+		case types["integer"] && n.Tag == "!!int",
+			types["number"] && (n.Tag == "!!int" || n.Tag == "!!float"),
+			types["boolean"] && (n.Tag == "!!bool" || n.Tag == "!!str" && yaml11Bools[n.Value]):
+			return problems
+		case types["string"] && n.Tag != "!!map" && n.Tag != "!!seq":
+			if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(n.Value) {
+				if s.Pattern == durationPattern {
+					return problem("%q isn't a duration such as 500ms or 2s", n.Value)
+				}
+				return problem("%q doesn't match %s", n.Value, s.Pattern)
+			}
+			return problems
+		}
+	}
+	names := make([]string, 0, len(types))
+	for t := range types {
+		names = append(names, t)
+	}
+	sort.Strings(names)
+	return problem("must be %s", strings.Join(names, " or "))
+}
+
+// yaml11Bools are the YAML 1.1 spellings of booleans, which the loader
+// still accepts for bool fields.
+var yaml11Bools = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true, "on": true, "On": true, "ON": true,
+	"n": true, "N": true, "no": true, "No": true, "NO": true, "off": true, "Off": true, "OFF": true,
+}
+
+func schemaTypes(s *Schema) map[string]bool {
+	types := make(map[string]bool)
+	switch t := s.Type.(type) {
+	case string:
+		types[t] = true
+	case []string:
+		for _, name := range t {
+			types[name] = true
+		}
+	}
+	return types
+}
+
+// unknownKey describes a key that isn't in the schema, pointing out keys
+// that were renamed or removed and otherwise suggesting a known key it may
+// be a typo of.
+func unknownKey(path, key string, s *Schema) string {
+	if c := keyChangeOf(strings.TrimPrefix(path+"."+key, ".")); c != nil {
+		return fmt.Sprintf("%s, gouix upgrade migrates it", c)
+	}
+	msg := fmt.Sprintf("unknown key %q", key)
+	if path != "" {
+		msg = fmt.Sprintf("unknown key %q in %s", key, path)
+	}
+	best, bestDist := "", 3
+	for name := range s.Properties {
+		if d := distance(key, name); d < bestDist || d == bestDist && best != "" && name < best {
+			best, bestDist = name, d
+		}
+	}
+	if best != "" {
+		msg += fmt.Sprintf(", did you mean %q?", best)
+	}
+	return msg
+}
+
+// distance is the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidateAgreesWithLoader(t *testing.T) {
+	tests := []string{
+		"build:\n  wasm_opt: true\n",
+		"build:\n  wasm_opt: yes\n",
+		"build:\n  wasm_opt: \"On\"\n",
+		"build:\n  wasm_opt: off\n",
+		"build:\n  wasm_opt: maybe\n",
+		"build:\n  opt: z\n",
+		"build:\n  opt: 3\n",
+		"server:\n  port: 3000\n",
+		"server:\n  port: http\n",
+		"server:\n  watch:\n    interval: 2s\n",
+		"server:\n  watch:\n    interval: soon\n",
+		"server:\n  watch:\n    interval: 500\n",
+	}
+	for _, src := range tests {
+		problems, err := Validate([]byte(src))
+		if err != nil {
+			t.Fatalf("Validate(%q): %s", src, err)
+		}
+		var cfg Config
+		loadErr := yaml.Unmarshal([]byte(src), &cfg)
+		// the loader doesn't check enums and durations, so only the
+		// values it accepts are compared
+		if loadErr == nil && len(problems) > 0 && !strings.Contains(problems[0].Message, "must be one of") && !strings.Contains(problems[0].Message, "duration") {
+			t.Errorf("Validate(%q) reports %s, but the loader accepts it", src, problems[0])
+		}
+		if loadErr != nil && len(problems) == 0 {
+			t.Errorf("Validate(%q) reports no problems, but the loader fails: %s", src, loadErr)
+		}
+	}
+}
@@ -1,68 +1,264 @@
 package create
 
 import (
+	"bytes"
 	"fmt"
 	"os"
-	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/fatih/color"
+	"github.com/goui-org/gouix/config"
+	"github.com/goui-org/gouix/editor"
 	"github.com/goui-org/gouix/files"
 	"github.com/goui-org/gouix/utils"
 )
 
-func Create(name string) error {
+// Options configure the project created by Create.
+type Options struct {
+	// Template is the name of a built in template or the path of a
+	// directory containing one.
+	Template string
+	// Vars override the defaults of the template's variables.
+	Vars map[string]string
+	// Module is the path of the new Go module. It defaults to the
+	// project's directory name.
+	Module string
+	// GouiVersion is the goui version to require, "latest" or vX.Y.Z.
+	GouiVersion string
+	// Force allows creating the project in a non-empty directory,
+	// overwriting files that are in the way.
+	Force bool
+	// DryRun lists the files that would be created without writing them.
+	DryRun bool
+	// WASMOpt enables wasm-opt in goui.yml.
+	WASMOpt bool
+	// Git initializes a git repository in the project.
+	Git bool
+	// SkipEditorSettings leaves out the VS Code settings.
+	SkipEditorSettings bool
+	// RunPostCreate runs the template's post_create commands. Otherwise
+	// they're listed for the user to run.
+	RunPostCreate bool
+}
+
+func Create(name string, opts *Options) error {
 	fail := func(err error) error {
 		return fmt.Errorf("create.Create: %w", err)
 	}
-	fmt.Printf("creating %s...\n", name)
-	if err := utils.Mkdir(path.Join(name, ".vscode"), path.Join(name, "src", "app"), path.Join(name, "public")); err != nil {
-		return fail(err)
+	if opts == nil {
+		opts = &Options{}
 	}
-	if err := utils.WriteFile(path.Join(name, ".vscode", "settings.json"), files.VSCodeSettingsJSON); err != nil {
+	if err := ValidateName(name); err != nil {
 		return fail(err)
 	}
-	if err := utils.WriteFile(path.Join(name, "public", "index.html"), files.IndexHTML); err != nil {
-		return fail(err)
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
 	}
-	if err := utils.WriteFile(path.Join(name, "public", "main.css"), files.MainCSS); err != nil {
+	tmpl, err := LoadTemplate(opts.Template)
+	if err != nil {
 		return fail(err)
 	}
-	if err := utils.WriteFile(path.Join(name, "go.mod"), files.GoMOD); err != nil {
+	vars, err := tmpl.Vars(opts.Vars)
+	if err != nil {
 		return fail(err)
 	}
-	if err := utils.WriteFile(path.Join(name, "goui.yml"), files.GoUIYML); err != nil {
+	if opts.Module == "" {
+		opts.Module = defaultModule(name)
+	}
+	if err := ValidateModule(opts.Module); err != nil {
 		return fail(err)
 	}
-	if err := utils.WriteFile(path.Join(name, "src", "main.go"), files.MainGO); err != nil {
+	empty, err := isEmptyDir(name)
+	if err != nil {
 		return fail(err)
 	}
-	if err := utils.WriteFile(path.Join(name, "src", "app", "app.go"), files.AppGO); err != nil {
+	if !empty && !opts.Force && !opts.DryRun {
+		return fail(fmt.Errorf("%s is not empty, use --force to create the project anyway", name))
+	}
+	gouiVersion, err := ResolveGouiVersion(opts.GouiVersion)
+	if err != nil {
 		return fail(err)
 	}
-	if err := utils.WriteFile(path.Join(name, ".gitignore"), files.GitIgnore); err != nil {
+	data := &TemplateData{Name: name, Module: opts.Module, GouiVersion: gouiVersion, Vars: vars}
+	goMod, err := render("go.mod", files.GoMOD, data)
+	if err != nil {
 		return fail(err)
 	}
-	if err := utils.WriteFile(path.Join(name, "README.md"), files.ReadmeMD); err != nil {
+	rendered, err := tmpl.Render(data)
+	if err != nil {
 		return fail(err)
 	}
+	gouiYML := files.GoUIYML
+	if opts.WASMOpt {
+		gouiYML = bytes.Replace(gouiYML, []byte("wasm_opt: false"), []byte("wasm_opt: true"), 1)
+	}
+	projectFiles := map[string][]byte{
+		".vscode/settings.json": editor.VSCodeSettings(),
+		"public/index.html":     files.IndexHTML,
+		"go.mod":                goMod,
+		"goui.yml":              gouiYML,
+		".gitignore":            files.GitIgnore,
+		editor.SchemaPath:       config.JSONSchemaBytes(),
+		"README.md":             files.ReadmeMD,
+	}
+	if opts.SkipEditorSettings {
+		delete(projectFiles, ".vscode/settings.json")
+	}
+	// template files take precedence over the common ones
+	for p, b := range rendered {
+		if opts.SkipEditorSettings && strings.HasPrefix(p, ".vscode/") {
+			continue
+		}
+		projectFiles[p] = b
+	}
+	var steps, skippedSteps []string
+	if opts.Git {
+		steps = append(steps, "git init")
+	}
+	if opts.RunPostCreate {
+		steps = append(steps, tmpl.PostCreate...)
+	} else {
+		skippedSteps = tmpl.PostCreate
+	}
+	paths := make([]string, 0, len(projectFiles))
+	for p := range projectFiles {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	if opts.DryRun {
+		dryRun(name, paths, steps, skippedSteps)
+		return nil
+	}
+	fmt.Printf("creating %s from the %s template...\n", name, opts.Template)
+	w := &writer{}
+	for _, p := range paths {
+		if err := w.write(filepath.Join(name, filepath.FromSlash(p)), projectFiles[p]); err != nil {
+			w.rollback()
+			return fail(err)
+		}
+	}
 	goGetSuccess := goGet(name)
+	failedSteps := skippedSteps
+	for _, step := range steps {
+		if err := runStep(name, step); err != nil {
+			failedSteps = append(failedSteps, step)
+		}
+	}
 	utils.ClearTerminal()
 	color.Green("Successfully created %s!\n\n", name)
 	fmt.Printf("To get started, run the following commands:\n\n")
 	color.Blue("\tcd %s\n", name)
 	if !goGetSuccess {
-		color.Blue("\tgo get main/src\n")
+		color.Blue("\tgo get ./src\n")
+	}
+	for _, step := range failedSteps {
+		color.Blue("\t%s\n", step)
 	}
 	color.Blue("\tgouix serve\n\n")
 	return nil
 }
 
-func goGet(name string) bool {
-	if err := os.Chdir(name); err != nil {
-		return false
+// defaultModule returns the module path used when none is given.
+func defaultModule(name string) string {
+	return filepath.Base(filepath.Clean(name))
+}
+
+func dryRun(name string, paths []string, steps []string, skippedSteps []string) {
+	fmt.Printf("would create %s:\n\n", name)
+	for _, p := range paths {
+		dst := filepath.Join(name, filepath.FromSlash(p))
+		if _, err := os.Stat(dst); err == nil {
+			color.Yellow("\t%s (overwrite)\n", dst)
+		} else {
+			fmt.Printf("\t%s\n", dst)
+		}
+	}
+	fmt.Printf("\nand run:\n\n")
+	for _, step := range append([]string{"go get ./src"}, steps...) {
+		color.Blue("\t%s\n", step)
+	}
+	if len(skippedSteps) > 0 {
+		fmt.Printf("\nbut not the template's post_create commands, allow them with --run-post-create:\n\n")
+		for _, step := range skippedSteps {
+			color.Yellow("\t%s\n", step)
+		}
 	}
-	if err := utils.Command("go", "get", "main/src"); err != nil {
+	fmt.Println()
+}
+
+func goGet(name string) bool {
+	if err := utils.CommandInDir(name, "go", "get", "./src"); err != nil {
 		return false
 	}
 	return true
 }
+
+func runStep(dir string, step string) error {
+	args, err := splitCommand(step)
+	if err != nil || len(args) == 0 {
+		return err
+	}
+	return utils.CommandInDir(dir, args[0], args[1:]...)
+}
+
+// splitCommand splits a command line into its arguments the way a POSIX
+// shell does, without expanding anything. Single quotes keep everything
+// up to the next one, and backslashes escape the next character outside
+// of them.
+func splitCommand(line string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, c := range line {
+		switch {
+		case escaped:
+			// in double quotes a backslash only escapes some characters
+			if quote == '"' && !strings.ContainsRune(`"\$`+"`", c) {
+				arg.WriteRune('\\')
+			}
+			arg.WriteRune(c)
+			escaped = false
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				arg.WriteRune(c)
+			}
+		case c == '\\':
+			escaped = true
+			inArg = true
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else {
+				arg.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(c)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in %q", quote, line)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash in %q", line)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
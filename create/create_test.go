@@ -0,0 +1,56 @@
+package create
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    []string
+		wantErr bool
+	}{
+		{"go mod tidy", []string{"go", "mod", "tidy"}, false},
+		{"  git   init  ", []string{"git", "init"}, false},
+		{`git commit -m "first commit"`, []string{"git", "commit", "-m", "first commit"}, false},
+		{`echo 'it''s' "a \"b\" \c"`, []string{"echo", "its", `a "b" \c`}, false},
+		{`echo 'no \escape'`, []string{"echo", `no \escape`}, false},
+		{`touch my\ file ""`, []string{"touch", "my file", ""}, false},
+		{`echo $HOME`, []string{"echo", "$HOME"}, false},
+		{"", nil, false},
+		{`echo "open`, nil, true},
+		{`echo 'open`, nil, true},
+		{`echo trailing\`, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := splitCommand(tt.line)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("splitCommand(%q) error = %v, want error %v", tt.line, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitCommand(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestResolveGouiVersion(t *testing.T) {
+	tests := []struct {
+		version, want string
+		wantErr       bool
+	}{
+		{"", DefaultGouiVersion, false},
+		{"v0.2.8", "v0.2.8", false},
+		{"v0.3.0-rc.1", "v0.3.0-rc.1", false},
+		{"vfoo", "", true},
+		{"v1.2.3.4", "", true},
+		{"0.2.8", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ResolveGouiVersion(tt.version)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ResolveGouiVersion(%q) = %q, %v, want %q, error %v", tt.version, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
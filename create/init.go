@@ -0,0 +1,173 @@
+package create
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/goui-org/gouix/config"
+	"github.com/goui-org/gouix/editor"
+	"github.com/goui-org/gouix/files"
+	"github.com/goui-org/gouix/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultEntry is the directory of the main package of new projects.
+const DefaultEntry = "src"
+
+// InitOptions configure Init.
+type InitOptions struct {
+	// Entry is the directory the main package is added to.
+	Entry string
+	// GouiVersion is the goui version to require, "latest" or vX.Y.Z.
+	GouiVersion string
+	// DryRun lists the changes without making them.
+	DryRun bool
+}
+
+// Init adds gouix to the Go module in the current directory. Files that
+// already exist are left alone, except for the VS Code settings, which
+// are merged.
+func Init(opts *InitOptions) error {
+	fail := func(err error) error {
+		return fmt.Errorf("create.Init: %w", err)
+	}
+	if opts == nil {
+		opts = &InitOptions{}
+	}
+	if opts.Entry == "" {
+		opts.Entry = DefaultEntry
+	}
+	entry := filepath.ToSlash(filepath.Clean(opts.Entry))
+	if filepath.IsAbs(opts.Entry) || entry == "." || strings.HasPrefix(entry, "../") {
+		return fail(fmt.Errorf("entry %q must be a directory inside the module", opts.Entry))
+	}
+	module, err := utils.ModulePath()
+	if err != nil {
+		return fail(err)
+	}
+	gouiVersion, err := ResolveGouiVersion(opts.GouiVersion)
+	if err != nil {
+		return fail(err)
+	}
+	minimal, err := LoadTemplate("minimal")
+	if err != nil {
+		return fail(err)
+	}
+	vars, err := minimal.Vars(nil)
+	if err != nil {
+		return fail(err)
+	}
+	rendered, err := minimal.Render(&TemplateData{Name: filepath.Base(module), Module: module, GouiVersion: gouiVersion, Vars: vars})
+	if err != nil {
+		return fail(err)
+	}
+	gouiYML := files.GoUIYML
+	if entry != DefaultEntry {
+		// marshaled so entries that mean something else in YAML are quoted
+		value, err := yaml.Marshal(entry)
+		if err != nil {
+			return fail(err)
+		}
+		gouiYML = bytes.Replace(gouiYML, []byte("build:\n"), append([]byte("build:\n  entry: "), value...), 1)
+	}
+	// settings that can't be parsed are left alone
+	settings, err := editor.MergeJSON(filepath.Join(".vscode", "settings.json"), editor.VSCodeSettings())
+	mergeSettings := !errors.Is(err, editor.ErrNotJSON)
+	if err != nil && mergeSettings {
+		return fail(err)
+	}
+	gitignore, err := appendLines(".gitignore", files.GitIgnore)
+	if err != nil {
+		return fail(err)
+	}
+	changes := []struct {
+		path string
+		data []byte
+		// merge marks files that are updated even if they exist
+		merge bool
+	}{
+		{path: "goui.yml", data: gouiYML},
+		{path: "public/index.html", data: files.IndexHTML},
+		{path: "public/main.css", data: rendered["public/main.css"]},
+		{path: entry + "/main.go", data: rendered["src/main.go"]},
+		{path: ".vscode/settings.json", data: settings, merge: mergeSettings},
+		{path: ".gitignore", data: gitignore, merge: true},
+		{path: editor.SchemaPath, data: config.JSONSchemaBytes(), merge: true},
+	}
+	fmt.Printf("adding gouix to %s...\n\n", module)
+	w := &writer{}
+	for _, c := range changes {
+		p := filepath.FromSlash(c.path)
+		old, err := os.ReadFile(p)
+		exists := err == nil
+		switch {
+		case exists && bytes.Equal(old, c.data):
+			continue
+		case exists && !c.merge:
+			fmt.Printf("\t%s exists, skipped\n", c.path)
+			continue
+		case c.data == nil:
+			continue
+		case exists:
+			color.Yellow("\tupdate %s\n", c.path)
+		default:
+			color.Green("\tcreate %s\n", c.path)
+		}
+		if opts.DryRun {
+			continue
+		}
+		if err := w.write(p, c.data); err != nil {
+			w.rollback()
+			return fail(err)
+		}
+	}
+	dep := gouiModule + "@" + gouiVersion
+	color.Blue("\tgo get %s\n\n", dep)
+	if opts.DryRun {
+		return nil
+	}
+	if err := utils.Command("go", "get", dep); err != nil {
+		w.rollback()
+		return fail(err)
+	}
+	color.Green("Added gouix to %s!\n\n", module)
+	fmt.Printf("To get started, run:\n\n")
+	color.Blue("\tgouix serve\n\n")
+	return nil
+}
+
+// appendLines adds the lines of add missing from file to its end. It
+// returns nil if nothing needs to change.
+func appendLines(file string, add []byte) ([]byte, error) {
+	b, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return add, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	have := make(map[string]bool)
+	for _, line := range strings.Split(string(b), "\n") {
+		have[strings.TrimSpace(line)] = true
+	}
+	out := b
+	for _, line := range strings.Split(string(add), "\n") {
+		if line = strings.TrimSpace(line); line == "" || have[line] {
+			continue
+		}
+		if len(out) > 0 && out[len(out)-1] != '\n' {
+			out = append(out, '\n')
+		}
+		out = append(out, line+"\n"...)
+	}
+	if len(out) == len(b) {
+		return nil, nil
+	}
+	return out, nil
+}
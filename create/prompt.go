@@ -0,0 +1,185 @@
+package create
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Prompter asks questions on a terminal.
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: bufio.NewReader(in), out: out}
+}
+
+func (p *Prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Ask asks for a string, returning def if the answer is empty. The
+// question is repeated until validate accepts the answer.
+func (p *Prompter) Ask(question string, def string, validate func(string) error) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(p.out, "%s %s ", question, color.HiBlackString("(%s)", def))
+		} else {
+			fmt.Fprintf(p.out, "%s ", question)
+		}
+		answer, err := p.readLine()
+		if err != nil {
+			return "", fmt.Errorf("create.Prompter.Ask: %w", err)
+		}
+		if answer == "" {
+			answer = def
+		}
+		if validate == nil {
+			return answer, nil
+		}
+		if err := validate(answer); err != nil {
+			color.New(color.FgRed).Fprintln(p.out, err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// Confirm asks a yes or no question.
+func (p *Prompter) Confirm(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		fmt.Fprintf(p.out, "%s %s ", question, color.HiBlackString("(%s)", hint))
+		answer, err := p.readLine()
+		if err != nil {
+			return false, fmt.Errorf("create.Prompter.Confirm: %w", err)
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// Choose asks for one of choices by name or number. Descriptions, if
+// given, are shown next to the choices.
+func (p *Prompter) Choose(question string, choices []string, descriptions []string, def string) (string, error) {
+	fmt.Fprintln(p.out, question)
+	for i, choice := range choices {
+		line := fmt.Sprintf("  %d) %s", i+1, choice)
+		if i < len(descriptions) && descriptions[i] != "" {
+			line += color.HiBlackString(" - %s", descriptions[i])
+		}
+		fmt.Fprintln(p.out, line)
+	}
+	return p.Ask("Choice", def, func(answer string) error {
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(choices) {
+			return nil
+		}
+		for _, choice := range choices {
+			if choice == answer {
+				return nil
+			}
+		}
+		return fmt.Errorf("choose one of 1-%d or a name", len(choices))
+	})
+}
+
+// Flags reports whether the flag with the given name was set, so prompts
+// are only shown for missing answers.
+type Flags func(name string) bool
+
+// Prompt asks for the answers not given as flags and returns the project
+// name.
+func Prompt(p *Prompter, name string, opts *Options, set Flags) (string, error) {
+	var err error
+	if name == "" {
+		if name, err = p.Ask("Project name:", "my-app", ValidateName); err != nil {
+			return "", err
+		}
+	}
+	if !set("template") {
+		names := Templates()
+		descriptions := make([]string, len(names))
+		for i, n := range names {
+			if t, err := LoadTemplate(n); err == nil {
+				descriptions[i] = t.Description
+			}
+		}
+		choice, err := p.Choose("Template:", names, descriptions, DefaultTemplate)
+		if err != nil {
+			return "", err
+		}
+		if n, err := strconv.Atoi(choice); err == nil {
+			choice = names[n-1]
+		}
+		opts.Template = choice
+	}
+	if !set("run-post-create") {
+		if opts.RunPostCreate, err = confirmPostCreate(p, opts.Template); err != nil {
+			return "", err
+		}
+	}
+	if !set("module") {
+		if opts.Module, err = p.Ask("Module path:", defaultModule(name), ValidateModule); err != nil {
+			return "", err
+		}
+	}
+	if !set("wasm-opt") {
+		if opts.WASMOpt, err = p.Confirm("Optimize builds with wasm-opt?", installed("wasm-opt")); err != nil {
+			return "", err
+		}
+	}
+	if !set("git") {
+		if opts.Git, err = p.Confirm("Initialize a git repository?", installed("git")); err != nil {
+			return "", err
+		}
+	}
+	if !set("editor-settings") {
+		write, err := p.Confirm("Write VS Code settings?", true)
+		if err != nil {
+			return "", err
+		}
+		opts.SkipEditorSettings = !write
+	}
+	fmt.Fprintln(p.out)
+	return name, nil
+}
+
+// confirmPostCreate lists the post_create commands of the template and
+// asks whether to run them.
+func confirmPostCreate(p *Prompter, name string) (bool, error) {
+	t, err := LoadTemplate(name)
+	if err != nil || len(t.PostCreate) == 0 {
+		// Create reports the broken template
+		return false, nil
+	}
+	fmt.Fprintln(p.out, "The template runs these commands in the new project:")
+	for _, step := range t.PostCreate {
+		fmt.Fprintf(p.out, "  %s\n", step)
+	}
+	return p.Confirm("Run them?", false)
+}
+
+func installed(program string) bool {
+	_, err := exec.LookPath(program)
+	return err == nil
+}
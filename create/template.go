@@ -0,0 +1,210 @@
+package create
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/goui-org/gouix/files"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultTemplate is used when no template is given.
+	DefaultTemplate = "counter"
+	templateFile    = "template.yml"
+	templateExt     = ".tmpl"
+)
+
+// Template is a project layout, either built in or read from a directory
+// such as a git checkout. Every file in it is copied into the new project.
+// Files ending in .tmpl are rendered as Go templates with TemplateData and
+// written without the extension.
+type Template struct {
+	Description string `yaml:"description"`
+	// Variables are the template's variables and their defaults.
+	Variables map[string]string `yaml:"variables"`
+	// Patterns are regular expressions the values of variables must
+	// match, for variables that can't hold just anything.
+	Patterns map[string]string `yaml:"patterns"`
+	// Files restricts which files are copied, as globs relative to the
+	// template. All files are copied when empty.
+	Files []string `yaml:"files"`
+	// PostCreate lists commands to run in the new project, split into
+	// arguments like a shell does but without running one. They only run
+	// once the user allows them.
+	PostCreate []string `yaml:"post_create"`
+	fsys       fs.FS
+}
+
+// TemplateData is passed to files ending in .tmpl.
+type TemplateData struct {
+	// Name is the project name.
+	Name string
+	// Module is the path of the project's Go module.
+	Module string
+	// GouiVersion is the required version of goui.
+	GouiVersion string
+	Vars        map[string]string
+}
+
+// Templates returns the names of the built in templates.
+func Templates() []string {
+	entries, err := fs.ReadDir(files.Templates, "templates")
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadTemplate loads a built in template by name, or the template in a
+// local directory if name is a path.
+func LoadTemplate(name string) (*Template, error) {
+	fail := func(err error) (*Template, error) {
+		return nil, fmt.Errorf("create.LoadTemplate: %w", err)
+	}
+	var fsys fs.FS
+	if isPath(name) {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return fail(err)
+		}
+		if !fi.IsDir() {
+			return fail(fmt.Errorf("%s is not a directory", name))
+		}
+		fsys = os.DirFS(name)
+	} else {
+		sub, err := fs.Sub(files.Templates, path.Join("templates", name))
+		if err != nil {
+			return fail(err)
+		}
+		if _, err := fs.Stat(sub, "."); err != nil {
+			return fail(fmt.Errorf("unknown template %q, choose one of %s", name, strings.Join(Templates(), ", ")))
+		}
+		fsys = sub
+	}
+	t := &Template{fsys: fsys}
+	b, err := fs.ReadFile(fsys, templateFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fail(err)
+	}
+	if err := yaml.Unmarshal(b, t); err != nil {
+		return fail(err)
+	}
+	for _, step := range t.PostCreate {
+		if _, err := splitCommand(step); err != nil {
+			return fail(fmt.Errorf("post_create: %w", err))
+		}
+	}
+	return t, nil
+}
+
+func isPath(name string) bool {
+	return strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".")
+}
+
+// Vars returns the template's variables with overrides applied, failing
+// on overrides of variables the template doesn't declare.
+func (t *Template) Vars(overrides map[string]string) (map[string]string, error) {
+	vars := make(map[string]string, len(t.Variables))
+	for k, v := range t.Variables {
+		vars[k] = v
+	}
+	for k, v := range overrides {
+		if _, ok := t.Variables[k]; !ok {
+			return nil, fmt.Errorf("create.Template.Vars: unknown variable %q", k)
+		}
+		vars[k] = v
+	}
+	for k, pattern := range t.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("create.Template.Vars: pattern of %q: %w", k, err)
+		}
+		if !re.MatchString(vars[k]) {
+			return nil, fmt.Errorf("create.Template.Vars: %q isn't a valid %s, it must match %s", vars[k], k, pattern)
+		}
+	}
+	return vars, nil
+}
+
+// Render returns the project files the template produces, keyed by their
+// slash separated path in the project.
+func (t *Template) Render(data *TemplateData) (map[string][]byte, error) {
+	fail := func(err error) (map[string][]byte, error) {
+		return nil, fmt.Errorf("create.Template.Render: %w", err)
+	}
+	out := make(map[string][]byte)
+	err := fs.WalkDir(t.fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if p == templateFile || !t.includes(p) {
+			return nil
+		}
+		b, err := fs.ReadFile(t.fsys, p)
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(p, templateExt) {
+			if b, err = render(p, b, data); err != nil {
+				return err
+			}
+			p = strings.TrimSuffix(p, templateExt)
+		}
+		out[p] = b
+		return nil
+	})
+	if err != nil {
+		return fail(err)
+	}
+	return out, nil
+}
+
+func render(name string, b []byte, data *TemplateData) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(b))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (t *Template) includes(p string) bool {
+	if len(t.Files) == 0 {
+		return true
+	}
+	for _, pattern := range t.Files {
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+		if strings.HasPrefix(p, strings.TrimSuffix(pattern, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
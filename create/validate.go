@@ -0,0 +1,62 @@
+package create
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// projectName matches names that are usable as both a directory and the
+// last element of a module path.
+var projectName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// modulePathElement matches one slash separated element of a module path.
+var modulePathElement = regexp.MustCompile(`^[A-Za-z0-9_~-]+(\.[A-Za-z0-9_~-]+)*$`)
+
+// ValidateName checks that name is a path whose last element is a valid
+// project name.
+func ValidateName(name string) error {
+	if name == "" {
+		return errors.New("create.ValidateName: missing project name")
+	}
+	base := filepath.Base(filepath.Clean(name))
+	if !projectName.MatchString(base) {
+		return fmt.Errorf("create.ValidateName: invalid project name %q, use letters, digits, '.', '_' and '-' and start with a letter or digit", base)
+	}
+	return nil
+}
+
+// ValidateModule checks that module is a valid Go module path.
+func ValidateModule(module string) error {
+	if module == "" {
+		return errors.New("create.ValidateModule: missing module path")
+	}
+	for _, elem := range strings.Split(module, "/") {
+		if !modulePathElement.MatchString(elem) {
+			return fmt.Errorf("create.ValidateModule: invalid module path %q", module)
+		}
+	}
+	return nil
+}
+
+// isEmptyDir reports whether dir doesn't exist or has no entries.
+func isEmptyDir(dir string) (bool, error) {
+	f, err := os.Open(dir)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	if _, err := f.Readdirnames(1); err == io.EOF {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	return false, nil
+}
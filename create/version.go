@@ -0,0 +1,63 @@
+package create
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+const (
+	gouiModule = "github.com/goui-org/goui"
+	// DefaultGouiVersion is used when the latest version can't be resolved.
+	DefaultGouiVersion = "v0.2.8"
+)
+
+// ResolveGouiVersion returns the goui version to require. "latest" is
+// looked up through GOPROXY, then in the local module cache, falling back
+// to DefaultGouiVersion when neither knows any versions.
+func ResolveGouiVersion(version string) (string, error) {
+	if version == "" {
+		return DefaultGouiVersion, nil
+	}
+	if version != "latest" {
+		if !semver.IsValid(version) {
+			return "", fmt.Errorf("create.ResolveGouiVersion: invalid version %q, expected latest or vX.Y.Z", version)
+		}
+		return version, nil
+	}
+	if v, err := latest(nil); err == nil {
+		return v, nil
+	}
+	out, err := exec.Command("go", "env", "GOMODCACHE").Output()
+	if err == nil {
+		cache := filepath.ToSlash(filepath.Join(strings.TrimSpace(string(out)), "cache", "download"))
+		if !strings.HasPrefix(cache, "/") {
+			cache = "/" + cache
+		}
+		if v, err := latest([]string{"GOPROXY=file://" + cache, "GOSUMDB=off"}); err == nil {
+			return v, nil
+		}
+	}
+	fmt.Printf("could not resolve the latest goui version, using %s\n", DefaultGouiVersion)
+	return DefaultGouiVersion, nil
+}
+
+func latest(env []string) (string, error) {
+	cmd := exec.Command("go", "list", "-m", "-f", "{{.Version}}", gouiModule+"@latest")
+	// run outside of any module so its requirements don't matter
+	cmd.Dir = os.TempDir()
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	v := strings.TrimSpace(string(out))
+	if v == "" {
+		return "", fmt.Errorf("no versions of %s", gouiModule)
+	}
+	return v, nil
+}
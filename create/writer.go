@@ -0,0 +1,63 @@
+package create
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/goui-org/gouix/utils"
+)
+
+// writer writes project files, remembering what it changed so a partially
+// created project can be rolled back.
+type writer struct {
+	// created holds the files and directories that didn't exist before,
+	// in the order they were created.
+	created []string
+	// backups holds the previous contents of overwritten files.
+	backups map[string][]byte
+}
+
+func (w *writer) mkdir(dir string) error {
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	if err := w.mkdir(filepath.Dir(dir)); err != nil {
+		return err
+	}
+	if err := utils.Mkdir(dir); err != nil {
+		return err
+	}
+	w.created = append(w.created, dir)
+	return nil
+}
+
+func (w *writer) write(p string, b []byte) error {
+	if err := w.mkdir(filepath.Dir(p)); err != nil {
+		return err
+	}
+	old, err := os.ReadFile(p)
+	switch {
+	case err == nil:
+		if w.backups == nil {
+			w.backups = make(map[string][]byte)
+		}
+		w.backups[p] = old
+	case errors.Is(err, os.ErrNotExist):
+		w.created = append(w.created, p)
+	default:
+		return err
+	}
+	return utils.WriteFile(p, b)
+}
+
+// rollback restores overwritten files and removes everything created, in
+// reverse order so directories are empty by the time they're removed.
+func (w *writer) rollback() {
+	for p, b := range w.backups {
+		utils.WriteFile(p, b)
+	}
+	for i := len(w.created) - 1; i >= 0; i-- {
+		os.Remove(w.created[i])
+	}
+}
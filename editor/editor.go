@@ -0,0 +1,192 @@
+package editor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/fatih/color"
+	"github.com/goui-org/gouix/config"
+	"github.com/goui-org/gouix/files"
+	"github.com/goui-org/gouix/utils"
+)
+
+// SchemaPath is where the JSON Schema of goui.yml is written in the
+// project, for the YAML language servers the editor settings wire up.
+const SchemaPath = ".gouix/goui.schema.json"
+
+// hints are printed after writing an editor's files, for settings that
+// can't be written to the project.
+var hints = map[string]string{
+	"goland": "Set Settings | Go | Build Tags | OS to js and Arch to wasm.",
+	"neovim": "Enable 'exrc' to load .nvim.lua, then use :GouixServe and :GouixBuild.",
+	"helix":  "Run gouix serve and gouix build in a terminal next to Helix.",
+}
+
+// Data is passed to the editor file templates.
+type Data struct {
+	// URL is the address of the development server.
+	URL string
+	// Schema is the path of the goui.yml schema in the project.
+	Schema string
+}
+
+// Options configure Write.
+type Options struct {
+	// Force overwrites files that can't be merged.
+	Force bool
+}
+
+// Editors returns the names of the supported editors.
+func Editors() []string {
+	entries, err := fs.ReadDir(files.Editors, "editors")
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Write adds the settings of the editor to the project in the current
+// directory: the env gopls needs to type check wasm code, tasks to run
+// gouix, and the goui.yml schema. JSON settings are merged into existing
+// ones, which may have comments. Other files, and JSON that can't be
+// parsed, are only written if they don't exist, unless forced.
+func Write(cfg *config.Config, name string, opts *Options) error {
+	fail := func(err error) error {
+		return fmt.Errorf("editor.Write: %w", err)
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	root := path.Join("editors", name)
+	if _, err := fs.Stat(files.Editors, root); err != nil {
+		return fail(fmt.Errorf("unknown editor %q, choose one of %s", name, strings.Join(Editors(), ", ")))
+	}
+	data := &Data{URL: cfg.Server.URL(), Schema: SchemaPath}
+	// everything is rendered and merged before writing, so a file that
+	// can't be merged doesn't leave the settings half written
+	type change struct {
+		path      string
+		data      []byte
+		overwrite bool
+	}
+	changes := []*change{{path: SchemaPath, data: config.JSONSchemaBytes(), overwrite: true}}
+	err := fs.WalkDir(files.Editors, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := files.Editors.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel := strings.TrimPrefix(p, root+"/")
+		if strings.HasSuffix(rel, ".tmpl") {
+			if b, err = render(p, b, data); err != nil {
+				return err
+			}
+			rel = strings.TrimSuffix(rel, ".tmpl")
+		}
+		if !strings.HasSuffix(rel, ".json") {
+			changes = append(changes, &change{path: rel, data: b, overwrite: opts.Force})
+			return nil
+		}
+		merged, err := MergeJSON(filepath.FromSlash(rel), b)
+		switch {
+		case errors.Is(err, ErrNotJSON):
+			// written over only when forced
+			changes = append(changes, &change{path: rel, data: b, overwrite: opts.Force})
+		case err != nil:
+			return err
+		case merged == nil:
+			changes = append(changes, &change{path: rel})
+		default:
+			changes = append(changes, &change{path: rel, data: merged, overwrite: true})
+		}
+		return nil
+	})
+	if err != nil {
+		return fail(err)
+	}
+	fmt.Printf("writing %s settings...\n\n", name)
+	for _, c := range changes {
+		if c.data == nil {
+			fmt.Printf("\t%s is up to date\n", c.path)
+			continue
+		}
+		if err := writeFile(c.path, c.data, c.overwrite); err != nil {
+			return fail(err)
+		}
+	}
+	if hint, ok := hints[name]; ok {
+		fmt.Printf("\n%s\n", hint)
+	}
+	fmt.Println()
+	return nil
+}
+
+// VSCodeSettings returns the VS Code settings.json that gouix create, init
+// and upgrade write, the same one editor-config merges.
+func VSCodeSettings() []byte {
+	const name = "editors/vscode/.vscode/settings.json.tmpl"
+	b, err := files.Editors.ReadFile(name)
+	if err == nil {
+		b, err = render(name, b, &Data{Schema: SchemaPath})
+	}
+	if err != nil {
+		panic(fmt.Sprintf("editor.VSCodeSettings: %s", err))
+	}
+	return b
+}
+
+func render(name string, b []byte, data *Data) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(b))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeFile writes b to the slash separated path p, leaving existing files
+// with other contents alone unless overwrite is set.
+func writeFile(p string, b []byte, overwrite bool) error {
+	file := filepath.FromSlash(p)
+	old, err := os.ReadFile(file)
+	switch {
+	case err == nil && bytes.Equal(old, b):
+		fmt.Printf("\t%s is up to date\n", p)
+		return nil
+	case err == nil && !overwrite:
+		fmt.Printf("\t%s exists, skipped (use --force to overwrite it)\n", p)
+		return nil
+	case err != nil && !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+	if err := utils.Mkdir(filepath.Dir(file)); err != nil {
+		return err
+	}
+	if err := utils.WriteFile(file, b); err != nil {
+		return err
+	}
+	if old != nil {
+		color.Yellow("\tupdate %s\n", p)
+	} else {
+		color.Green("\tcreate %s\n", p)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package editor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrNotJSON is returned by MergeJSON when the existing file can't be
+// parsed, even with its comments and trailing commas removed.
+var ErrNotJSON = errors.New("not JSON")
+
+// arrayKeys identify the elements of arrays of objects when merging, such
+// as tasks by label and launch configurations by name.
+var arrayKeys = []string{"label", "name"}
+
+// MergeJSON adds the keys of the JSON object ours that the one in file
+// doesn't have, keeping the values file has. Nested objects are merged
+// too, and so are arrays of objects with a label or name. The file may have comments and trailing
+// commas, as VS Code allows, but they are dropped if it changes. It
+// returns ours if file doesn't exist and nil if nothing needs to change.
+func MergeJSON(file string, ours []byte) ([]byte, error) {
+	fail := func(err error) ([]byte, error) {
+		return nil, fmt.Errorf("editor.MergeJSON: %w", err)
+	}
+	b, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return ours, nil
+	}
+	if err != nil {
+		return fail(err)
+	}
+	var theirs, add map[string]any
+	if err := json.Unmarshal(stripJSONC(b), &theirs); err != nil {
+		return fail(fmt.Errorf("can't merge %s: %w: %s", file, ErrNotJSON, err))
+	}
+	if theirs == nil {
+		theirs = make(map[string]any)
+	}
+	if err := json.Unmarshal(ours, &add); err != nil {
+		return fail(err)
+	}
+	if !mergeObject(theirs, add) {
+		return nil, nil
+	}
+	out, err := json.MarshalIndent(theirs, "", "    ")
+	if err != nil {
+		return fail(err)
+	}
+	return append(out, '\n'), nil
+}
+
+// mergeObject adds the keys of src that dst doesn't have, reporting
+// whether dst changed. Values dst already has are kept, even if they
+// differ, so the user's settings win.
+func mergeObject(dst, src map[string]any) bool {
+	changed := false
+	for k, v := range src {
+		d, ok := dst[k]
+		if !ok {
+			dst[k] = v
+			changed = true
+			continue
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			if d, ok := d.(map[string]any); ok {
+				changed = mergeObject(d, v) || changed
+			}
+		case []any:
+			if d, ok := d.([]any); ok {
+				if merged, ok := mergeArray(d, v); ok && len(merged) != len(d) {
+					dst[k] = merged
+					changed = true
+				}
+			}
+		}
+	}
+	return changed
+}
+
+// mergeArray appends the elements of src whose key isn't in dst yet. It
+// reports false if the elements have no key to merge by.
+func mergeArray(dst, src []any) ([]any, bool) {
+	key := func(v any) (string, bool) {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		for _, k := range arrayKeys {
+			if s, ok := obj[k].(string); ok {
+				return k + "=" + s, true
+			}
+		}
+		return "", false
+	}
+	have := make(map[string]bool)
+	for _, v := range dst {
+		if k, ok := key(v); ok {
+			have[k] = true
+		}
+	}
+	out := dst
+	for _, v := range src {
+		k, ok := key(v)
+		if !ok {
+			return nil, false
+		}
+		if !have[k] {
+			out = append(out, v)
+		}
+	}
+	return out, true
+}
+
+// stripJSONC removes the comments and trailing commas of JSON with
+// comments, leaving strings alone.
+func stripJSONC(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	// comma is the index in out of a comma that may be trailing
+	comma := -1
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case c == '"':
+			start := i
+			for i++; i < len(b) && b[i] != '"'; i++ {
+				if b[i] == '\\' {
+					i++
+				}
+			}
+			out = append(out, b[start:min(i+1, len(b))]...)
+			comma = -1
+		case c == '/' && i+1 < len(b) && b[i+1] == '/':
+			for i < len(b) && b[i] != '\n' {
+				i++
+			}
+			i--
+		case c == '/' && i+1 < len(b) && b[i+1] == '*':
+			end := bytes.Index(b[i+2:], []byte("*/"))
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			out = append(out, c)
+		case (c == '}' || c == ']') && comma >= 0:
+			out[comma] = ' '
+			out = append(out, c)
+			comma = -1
+		case c == ',':
+			comma = len(out)
+			out = append(out, c)
+		default:
+			out = append(out, c)
+			comma = -1
+		}
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+package editor
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestStripJSONC(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`{"a": 1}`, `{"a": 1}`},
+		{"{\n// comment\n\"a\": 1\n}", "{\n\n\"a\": 1\n}"},
+		{`{"a": /* inline */ 1}`, `{"a":  1}`},
+		{`{"a": [1, 2,], "b": 3,}`, `{"a": [1, 2 ], "b": 3 }`},
+		{"{\"a\": 1, // last\n}", "{\"a\": 1  \n}"},
+		{`{"url": "http://x//y", "s": "/* no */"}`, `{"url": "http://x//y", "s": "/* no */"}`},
+		{`{"q": "a\"//b", "c": 1}`, `{"q": "a\"//b", "c": 1}`},
+		{`{"s": ",}"}`, `{"s": ",}"}`},
+	}
+	for _, tt := range tests {
+		if got := string(stripJSONC([]byte(tt.in))); got != tt.want {
+			t.Errorf("stripJSONC(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMergeObject(t *testing.T) {
+	tests := []struct {
+		name        string
+		dst, src    string
+		want        string
+		wantChanged bool
+	}{
+		{
+			name:        "adds missing keys",
+			dst:         `{"a": 1}`,
+			src:         `{"a": 1, "b": 2}`,
+			want:        `{"a": 1, "b": 2}`,
+			wantChanged: true,
+		},
+		{
+			name: "keeps conflicting scalars",
+			dst:  `{"go.buildFlags": ["-tags=dev"], "editor.tabSize": 2, "gopls": {"build.env": {"GOOS": "linux"}}}`,
+			src:  `{"go.buildFlags": [], "editor.tabSize": 4, "gopls": {"build.env": {"GOOS": "js"}}}`,
+			want: `{"go.buildFlags": ["-tags=dev"], "editor.tabSize": 2, "gopls": {"build.env": {"GOOS": "linux"}}}`,
+		},
+		{
+			name:        "merges nested objects",
+			dst:         `{"gopls": {"build.env": {"GOOS": "linux"}}}`,
+			src:         `{"gopls": {"build.env": {"GOOS": "js", "GOARCH": "wasm"}}}`,
+			want:        `{"gopls": {"build.env": {"GOOS": "linux", "GOARCH": "wasm"}}}`,
+			wantChanged: true,
+		},
+		{
+			name:        "appends array elements by name",
+			dst:         `{"tasks": [{"label": "serve", "command": "mine"}]}`,
+			src:         `{"tasks": [{"label": "serve", "command": "gouix serve"}, {"label": "build"}]}`,
+			want:        `{"tasks": [{"label": "serve", "command": "mine"}, {"label": "build"}]}`,
+			wantChanged: true,
+		},
+		{
+			name: "keeps a different type",
+			dst:  `{"a": "x"}`,
+			src:  `{"a": {"b": 1}}`,
+			want: `{"a": "x"}`,
+		},
+	}
+	for _, tt := range tests {
+		var dst, src, want map[string]any
+		for _, v := range []struct {
+			s string
+			m *map[string]any
+		}{{tt.dst, &dst}, {tt.src, &src}, {tt.want, &want}} {
+			if err := json.Unmarshal([]byte(v.s), v.m); err != nil {
+				t.Fatal(err)
+			}
+		}
+		changed := mergeObject(dst, src)
+		if changed != tt.wantChanged || !reflect.DeepEqual(dst, want) {
+			t.Errorf("%s: mergeObject() = %v, %v, want %v, %v", tt.name, dst, changed, want, tt.wantChanged)
+		}
+	}
+}
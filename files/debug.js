@@ -1,13 +1,159 @@
-const ws = new WebSocket('ws://' + window.location.host + '/hot')
-ws.onmessage = e => {
-	if (e.data === 'reload') return window.location.reload()
-	const overlay = document.createElement('div')
-	overlay.style = 'position: fixed; left: 0; right: 0; top: 0; bottom: 0; background: #000c; color: #e77; font-size: 18px'
+const PROTOCOL_VERSION = 1
+const RECONNECT_INTERVAL = 1000
+const MAX_OUTBOX = 100
+const hotURL = (window.location.protocol === 'https:' ? 'wss://' : 'ws://') + window.location.host + '/hot'
+let ws
+let outbox = []
+const send = (type, data) => {
+	const payload = JSON.stringify({ v: PROTOCOL_VERSION, type, ...data })
+	if (ws && ws.readyState === WebSocket.OPEN) ws.send(payload)
+	else if (outbox.push(payload) > MAX_OUTBOX) outbox.shift()
+}
+
+const format = args => args.map(arg => {
+	if (arg instanceof Error) return arg.message
+	if (typeof arg === 'string') return arg
+	try {
+		return JSON.stringify(arg)
+	} catch (err) {
+		return String(arg)
+	}
+}).join(' ')
+const stackOf = args => {
+	const err = args.find(arg => arg instanceof Error)
+	return err ? err.stack : undefined
+}
+const forward = (level, text, stack) => send('console', { level, text, stack })
+
+for (const level of ['error', 'warn']) {
+	const original = console[level]
+	console[level] = (...args) => {
+		forward(level, format(args), stackOf(args))
+		original.apply(console, args)
+	}
+}
+
+// wasm_exec.js writes Go's stdout and stderr to console.log one line at a
+// time, so runtime panics are picked out of the log by their prefix.
+// The trace that follows is made of indented lines, blank lines between
+// goroutines, and frames; the first line of another shape ends it.
+const goPanic = /^(panic: |fatal error: |runtime error: |\[recovered\]|goroutine \d+ \[)/
+const goTrace = /^(\s|$|\[signal |[\w.\/*()\[\]-]+\(.*\)$|created by |exit status )/
+let panicking = false
+const log = console.log
+console.log = (...args) => {
+	const text = format(args)
+	if (goPanic.test(text)) panicking = true
+	else if (panicking && !goTrace.test(text)) panicking = false
+	if (panicking) forward('panic', text)
+	log.apply(console, args)
+}
+
+window.addEventListener('error', e => forward('exception', e.message, e.error && e.error.stack))
+window.addEventListener('unhandledrejection', e => {
+	const reason = e.reason
+	forward('rejection', reason instanceof Error ? reason.message : format([reason]), reason && reason.stack)
+})
+
+let overlay
+const clearOverlay = () => {
+	if (overlay) overlay.remove()
+	overlay = null
+}
+const showError = text => {
+	clearOverlay()
+	overlay = document.createElement('div')
+	overlay.style = 'position: fixed; left: 0; right: 0; top: 0; bottom: 0; background: #000c; color: #e77; font-size: 18px; z-index: 2147483647'
 	const msg = document.createElement('div')
-	msg.style = 'width: 100%; max-width: 600px; margin: auto; margin-top: 5vh; line-height: 200%; padding: 0 20px;'
-	msg.innerText = e.data
+	msg.style = 'width: 100%; max-width: 600px; margin: auto; margin-top: 5vh; line-height: 200%; padding: 0 20px; white-space: pre-wrap'
+	msg.innerText = text
 	overlay.appendChild(msg)
 	document.body.appendChild(overlay)
 }
-ws.onopen = () => ws.send('loaded')
-ws.onclose = () => window.close()
+
+let badge
+const showBadge = (text, color) => {
+	if (!badge) {
+		badge = document.createElement('div')
+		badge.style = 'position: fixed; right: 10px; bottom: 10px; padding: 4px 10px; border-radius: 4px; font: 12px monospace; color: #fff; z-index: 2147483647'
+		document.body.appendChild(badge)
+	}
+	badge.style.background = color
+	badge.innerText = text
+}
+const hideBadge = () => {
+	if (badge) badge.remove()
+	badge = null
+}
+
+const refreshStylesheets = paths => {
+	for (const link of document.querySelectorAll('link[rel="stylesheet"]')) {
+		const url = new URL(link.href)
+		if (!paths.includes(url.pathname.replace(/^\//, ''))) continue
+		url.searchParams.set('t', Date.now())
+		link.href = url.href
+	}
+}
+
+// swap replaces the running instance with a freshly compiled one, keeping
+// the state the app saved with hotSave. It falls back to a full reload if
+// the app can't be torn down or the new module fails to start.
+const swap = url => {
+	if (!teardown()) return window.location.reload()
+	start(url).then(() => {
+		clearOverlay()
+		hideBadge()
+	}).catch(err => {
+		console.warn('gouix: hot reload failed, reloading', err)
+		window.location.reload()
+	})
+}
+
+const handlers = {
+	reload: () => window.location.reload(),
+	swap: msg => swap(msg.url),
+	css: msg => refreshStylesheets(msg.paths || []),
+	error: msg => {
+		hideBadge()
+		showError(msg.error)
+	},
+	building: () => showBadge('building...', '#25697b'),
+	built: msg => {
+		clearOverlay()
+		showBadge('built in ' + msg.duration, '#2e7d32')
+	},
+	runtime_error: msg => showError(msg.stack || msg.text),
+	stopped: () => showBadge('server stopped, reconnecting...', '#b71c1c'),
+	ping: () => {},
+}
+
+const onMessage = e => {
+	let msg
+	try {
+		msg = JSON.parse(e.data)
+	} catch (err) {
+		return console.warn('gouix: malformed message', e.data)
+	}
+	if (msg.v !== PROTOCOL_VERSION) return console.warn('gouix: unsupported protocol version', msg.v)
+	const handler = handlers[msg.type]
+	if (handler) handler(msg)
+}
+
+// connect opens the hot reload socket. When the server goes away a banner
+// is shown until it comes back, and the page is then reloaded to pick up
+// whatever changed in the meantime.
+const connect = reconnecting => {
+	ws = new WebSocket(hotURL)
+	ws.onmessage = onMessage
+	ws.onopen = () => {
+		if (reconnecting) return window.location.reload()
+		send('loaded', { url: window.location.pathname })
+		for (const payload of outbox) ws.send(payload)
+		outbox = []
+	}
+	ws.onclose = () => {
+		handlers.stopped()
+		setTimeout(() => connect(true), RECONNECT_INTERVAL)
+	}
+}
+connect(false)
@@ -1,6 +1,6 @@
 package files
 
-import _ "embed"
+import "embed"
 
 //go:embed wasmfetch.js
 var WasmFetchJS []byte
@@ -8,29 +8,36 @@ var WasmFetchJS []byte
 //go:embed debug.js
 var DebugJS []byte
 
+//go:embed hot.js
+var HotJS []byte
+
 //go:embed index.html
 var IndexHTML []byte
 
 //go:embed readme.md
 var ReadmeMD []byte
 
-//go:embed main.go_
-var MainGO []byte
-
-//go:embed app.go_
-var AppGO []byte
-
-//go:embed main.css
-var MainCSS []byte
-
 //go:embed go.mod_
 var GoMOD []byte
 
-//go:embed vscodesettings.json
-var VSCodeSettingsJSON []byte
-
 //go:embed gitignore
 var GitIgnore []byte
 
 //go:embed goui.yml
 var GoUIYML []byte
+
+// Templates holds the built in project templates, one per directory.
+//
+//go:embed all:templates
+var Templates embed.FS
+
+// Generators holds the default templates of gouix generate.
+//
+//go:embed generators
+var Generators embed.FS
+
+// Editors holds the project settings of each supported editor, laid out as
+// they are written to the project. Files ending in .tmpl are templates.
+//
+//go:embed all:editors
+var Editors embed.FS
@@ -0,0 +1,32 @@
+// hot.js is only bundled by the development server. It implements the
+// snapshot imports that wasmfetch.js stubs out, and teardown for swap.
+
+// hotState holds snapshots saved by the app so they survive swapping in a
+// freshly compiled instance. Values are raw bytes, copied out of memory.
+const hotState = new Map()
+window._GOUI_HOT = {
+	hotSave: (addr, len, addr2, len2) => {
+		hotState.set(getString(addr, len), new Uint8Array(memory.buffer, addr2, len2).slice())
+	},
+	hotLoad: (addr, len, buf, cap) => {
+		const data = hotState.get(getString(addr, len))
+		if (!data) return -1
+		if (data.length <= cap) new Uint8Array(memory.buffer, buf, data.length).set(data)
+		return data.length
+	},
+}
+
+// teardown unmounts the running instance so another can take its place. It
+// returns false if the app doesn't export hotTeardown to stop its work.
+const teardown = () => {
+	if (!exports || !exports.hotTeardown) return false
+	exports.hotTeardown()
+	if (root) {
+		root.removeEventListener('click', onRootClick)
+		root.replaceChildren()
+	}
+	elements = {}
+	nodes = new Map()
+	window._GOUI_ELEMENTS = elements
+	return true
+}
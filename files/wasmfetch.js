@@ -30,8 +30,19 @@ let decoder = new TextDecoder();
 let memory;
 let exports;
 let getString = (addr, len) => len ? decoder.decode(memory.buffer.slice(addr, addr + len)) : '';
-let go = new Go();
-Object.assign(go.importObject.gojs, {
+let root;
+let onRootClick = e => {
+    window._GOUI_EVENT = e;
+    let target = e.target;
+    while (target && target != root) {
+        let node = nodes.get(target);
+        if (node) {
+            exports.callClickListener(node);
+        }
+        target = target.parentNode;
+    }
+};
+let gojs = {
     createElement: (addr, len, clicks) => createElement(getString(addr, len), clicks),
     createTd: clicks => createElement('td', clicks),
     createTr: clicks => createElement('tr', clicks),
@@ -103,25 +114,24 @@ Object.assign(go.importObject.gojs, {
         }
     },
     mount: (node, addr, len) => {
-        let root = document.querySelector(getString(addr, len));
+        root = document.querySelector(getString(addr, len));
         root.appendChild(elements[node]);
-        root.addEventListener('click', e => {
-            window._GOUI_EVENT = e;
-            let target = e.target;
-            while (target && target != root) {
-                let node = nodes.get(target);
-                if (node) {
-                    exports.callClickListener(node);
-                }
-                target = target.parentNode;
-            }
-        });
+        root.addEventListener('click', onRootClick);
     },
-});
+    // the development server replaces these with the ones in hot.js
+    hotSave: () => {},
+    hotLoad: () => -1,
+};
+
+let start = url => {
+    let go = new Go();
+    Object.assign(go.importObject.gojs, gojs, window._GOUI_HOT);
+    return WebAssembly.instantiateStreaming(fetch(url), go.importObject).then(o => {
+        let instance = o.instance;
+        exports = instance.exports;
+        memory = exports.memory;
+        go.run(instance);
+    });
+};
 
-WebAssembly.instantiateStreaming(fetch('main.wasm'), go.importObject).then(o => {
-    let instance = o.instance;
-    exports = instance.exports;
-    memory = exports.memory;
-    go.run(instance);
-});
+start('main.wasm');
@@ -0,0 +1,110 @@
+package generate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/fatih/color"
+	"github.com/goui-org/gouix/config"
+	"github.com/goui-org/gouix/utils"
+)
+
+// ComponentOptions configure Component.
+type ComponentOptions struct {
+	Options
+	// CSS also creates a stylesheet for the component and imports it
+	// from public/main.css.
+	CSS bool
+}
+
+// Component creates a component from a slash separated path relative to
+// the entry directory, eg. components/UserCard creates UserCard in
+// src/components/user_card.go. Components without a directory go into
+// components.
+func Component(cfg *config.Config, name string, opts *ComponentOptions) error {
+	fail := func(err error) error {
+		return fmt.Errorf("generate.Component: %w", err)
+	}
+	if opts == nil {
+		opts = &ComponentOptions{}
+	}
+	dir, base := path.Split(strings.Trim(filepath.ToSlash(name), "/"))
+	dir = strings.TrimSuffix(dir, "/")
+	if dir == "" {
+		dir = "components"
+	}
+	if strings.HasPrefix(path.Clean(dir), "..") {
+		return fail(fmt.Errorf("%s must be inside the entry directory", name))
+	}
+	ws := words(base)
+	if len(ws) == 0 || !unicode.IsLetter(rune(ws[0][0])) {
+		return fail(fmt.Errorf("invalid component name %q", base))
+	}
+	goDir := filepath.Join(cfg.Build.Entry, filepath.FromSlash(dir))
+	data := &Data{
+		Package: packageName(goDir),
+		Name:    exported(ws),
+		Class:   strings.Join(ws, "-"),
+	}
+	goFile := filepath.Join(goDir, strings.Join(ws, "_")+".go")
+	b, err := render("component.go.tmpl", data)
+	if err != nil {
+		return fail(err)
+	}
+	if err := write(goFile, b, &opts.Options); err != nil {
+		return fail(err)
+	}
+	if !opts.CSS {
+		return nil
+	}
+	css, err := render("component.css.tmpl", data)
+	if err != nil {
+		return fail(err)
+	}
+	cssPath := path.Join(dir, data.Class+".css")
+	if err := write(filepath.Join("public", filepath.FromSlash(cssPath)), css, &opts.Options); err != nil {
+		return fail(err)
+	}
+	if err := importCSS(filepath.Join("public", "main.css"), cssPath); err != nil {
+		return fail(err)
+	}
+	return nil
+}
+
+// importCSS adds an @import of href to the stylesheet in file, after any
+// existing imports since they must come before other rules.
+func importCSS(file string, href string) error {
+	b, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	line := fmt.Sprintf("@import %q;\n", href)
+	if bytes.Contains(b, []byte(strings.TrimSpace(line))) {
+		return nil
+	}
+	lines := strings.SplitAfter(string(b), "\n")
+	at := 0
+	for i, l := range lines {
+		if t := strings.TrimSpace(l); strings.HasPrefix(t, "@import") || strings.HasPrefix(t, "@charset") {
+			at = i + 1
+		}
+	}
+	if at > 0 && !strings.HasSuffix(lines[at-1], "\n") {
+		lines[at-1] += "\n"
+	}
+	out := strings.Join(lines[:at], "") + line + strings.Join(lines[at:], "")
+	if err := utils.WriteFile(file, []byte(out)); err != nil {
+		return err
+	}
+	color.Yellow("\tupdate %s\n", filepath.ToSlash(file))
+	return nil
+}
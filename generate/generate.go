@@ -0,0 +1,149 @@
+package generate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/fatih/color"
+	"github.com/goui-org/gouix/files"
+	"github.com/goui-org/gouix/utils"
+)
+
+// TemplateDir is the project directory whose templates replace the
+// defaults, eg. .gouix/templates/component.go.tmpl.
+const TemplateDir = ".gouix/templates"
+
+// Data is passed to the generator templates.
+type Data struct {
+	// Package is the name of the generated file's package.
+	Package string
+	// Name is the exported name of the component or page function.
+	Name string
+	// Class is the CSS class of a component.
+	Class string
+	// Route is the route of a page.
+	Route string
+	// Title is the human readable name of a page.
+	Title string
+}
+
+// Options configure the generators.
+type Options struct {
+	// Force overwrites existing files.
+	Force bool
+}
+
+// render executes the template with the given name, preferring the
+// project's copy in TemplateDir.
+func render(name string, data *Data) ([]byte, error) {
+	b, err := os.ReadFile(filepath.Join(filepath.FromSlash(TemplateDir), name))
+	if errors.Is(err, os.ErrNotExist) {
+		b, err = files.Generators.ReadFile(path.Join("generators", name))
+	}
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(b))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(name, ".go.tmpl") {
+		formatted, err := format.Source(buf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return formatted, nil
+	}
+	return buf.Bytes(), nil
+}
+
+// write creates file, refusing to overwrite it unless forced.
+func write(file string, b []byte, opts *Options) error {
+	if _, err := os.Stat(file); err == nil && !opts.Force {
+		return fmt.Errorf("%s exists, use --force to overwrite it", file)
+	}
+	if err := utils.Mkdir(filepath.Dir(file)); err != nil {
+		return err
+	}
+	if err := utils.WriteFile(file, b); err != nil {
+		return err
+	}
+	color.Green("\tcreate %s\n", filepath.ToSlash(file))
+	return nil
+}
+
+// packageName returns the package of the Go files in dir, or a name
+// derived from the directory if there are none.
+func packageName(dir string) string {
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, name), nil, parser.PackageClauseOnly)
+		if err == nil {
+			return f.Name.Name
+		}
+	}
+	var b strings.Builder
+	for _, r := range strings.ToLower(filepath.Base(dir)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) && b.Len() > 0 {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return "main"
+	}
+	return b.String()
+}
+
+// words splits a name such as UserCard, user-card or user_card into its
+// lower case words.
+func words(name string) []string {
+	var out []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			out = append(out, strings.ToLower(string(cur)))
+			cur = nil
+		}
+	}
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])):
+			flush()
+			cur = append(cur, r)
+		default:
+			cur = append(cur, r)
+		}
+	}
+	flush()
+	return out
+}
+
+// exported joins words into an exported Go identifier.
+func exported(ws []string) string {
+	var b strings.Builder
+	for _, w := range ws {
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return b.String()
+}
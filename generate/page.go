@@ -0,0 +1,226 @@
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fatih/color"
+	"github.com/goui-org/gouix/config"
+	"github.com/goui-org/gouix/utils"
+)
+
+// PageOptions configure Page.
+type PageOptions struct {
+	Options
+	// Dir is the directory of the page packages, defaulting to app/pages
+	// in the entry directory.
+	Dir string
+	// Router is the file with the routes map the page is registered in,
+	// defaulting to app/router.go in the entry directory.
+	Router string
+}
+
+// Page creates a page for route and registers it in the routes map of
+// the router file, and in navLinks if the router has one. Projects
+// without a router file only get the page.
+func Page(cfg *config.Config, route string, opts *PageOptions) error {
+	fail := func(err error) error {
+		return fmt.Errorf("generate.Page: %w", err)
+	}
+	if opts == nil {
+		opts = &PageOptions{}
+	}
+	if opts.Dir == "" {
+		opts.Dir = filepath.Join(cfg.Build.Entry, "app", "pages")
+	}
+	if opts.Router == "" {
+		opts.Router = filepath.Join(cfg.Build.Entry, "app", "router.go")
+	}
+	route = "/" + strings.Trim(route, "/")
+	ws := words(route)
+	if route == "/" {
+		ws = []string{"home"}
+	}
+	if len(ws) == 0 || !unicode.IsLetter(rune(ws[0][0])) {
+		return fail(fmt.Errorf("invalid route %q", route))
+	}
+	title := make([]string, len(ws))
+	for i, w := range ws {
+		title[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	data := &Data{
+		Package: packageName(opts.Dir),
+		Name:    exported(ws),
+		Route:   route,
+		Title:   strings.Join(title, " "),
+	}
+	b, err := render("page.go.tmpl", data)
+	if err != nil {
+		return fail(err)
+	}
+	var router []byte
+	if _, err := os.Stat(opts.Router); err == nil {
+		if router, err = register(opts.Router, opts.Dir, data); err != nil {
+			return fail(err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fail(err)
+	}
+	if err := write(filepath.Join(opts.Dir, strings.Join(ws, "_")+".go"), b, &opts.Options); err != nil {
+		return fail(err)
+	}
+	if router == nil {
+		fmt.Printf("\nno router at %s, add the page to your routes yourself\n", filepath.ToSlash(opts.Router))
+		return nil
+	}
+	if err := utils.WriteFile(opts.Router, router); err != nil {
+		return fail(err)
+	}
+	color.Yellow("\tregister %s in %s\n", route, filepath.ToSlash(opts.Router))
+	return nil
+}
+
+type edit struct {
+	offset int
+	text   string
+}
+
+// register returns the router file with the page added to its routes map
+// and navLinks slice, importing the page package if needed.
+func register(router string, pagesDir string, data *Data) ([]byte, error) {
+	src, err := os.ReadFile(router)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, router, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	offset := func(p token.Pos) int {
+		return fset.Position(p).Offset
+	}
+	fn := data.Name
+	if filepath.Clean(filepath.Dir(router)) != filepath.Clean(pagesDir) {
+		fn = data.Package + "." + data.Name
+		importPath, err := packageImportPath(pagesDir)
+		if err != nil {
+			return nil, err
+		}
+		if !imports(f, importPath) {
+			src = applyEdits(src, []edit{addImport(f, offset, importPath)})
+			if f, err = parser.ParseFile(fset, router, src, parser.ParseComments); err != nil {
+				return nil, err
+			}
+		}
+	}
+	routes := compositeLit(f, "routes")
+	if routes == nil {
+		return nil, fmt.Errorf("no routes map in %s", router)
+	}
+	for _, elt := range routes.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if lit, ok := kv.Key.(*ast.BasicLit); ok && lit.Value == strconv.Quote(data.Route) {
+				return nil, fmt.Errorf("%s already has a route for %s", router, data.Route)
+			}
+		}
+	}
+	// addElement is an edit adding an element to the end of lit
+	addElement := func(lit *ast.CompositeLit, elt string) edit {
+		if n := len(lit.Elts); n > 0 {
+			between := src[offset(lit.Elts[n-1].End()):offset(lit.Rbrace)]
+			if !strings.Contains(string(between), ",") {
+				elt = ", " + elt
+			}
+		}
+		return edit{offset(lit.Rbrace), elt + ",\n"}
+	}
+	edits := []edit{addElement(routes, fmt.Sprintf("%q: %s", data.Route, fn))}
+	if links := compositeLit(f, "navLinks"); links != nil {
+		edits = append(edits, addElement(links, fmt.Sprintf("{%q, %q}", data.Route, data.Title)))
+	}
+	return format.Source(applyEdits(src, edits))
+}
+
+// applyEdits inserts text at the offsets, starting from the end so the
+// earlier offsets stay valid.
+func applyEdits(src []byte, edits []edit) []byte {
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].offset > edits[j].offset
+	})
+	out := string(src)
+	for _, e := range edits {
+		out = out[:e.offset] + e.text + out[e.offset:]
+	}
+	return []byte(out)
+}
+
+// compositeLit returns the value of the package level variable name if it
+// is a composite literal.
+func compositeLit(f *ast.File, name string) *ast.CompositeLit {
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, n := range vs.Names {
+				if n.Name != name || i >= len(vs.Values) {
+					continue
+				}
+				if lit, ok := vs.Values[i].(*ast.CompositeLit); ok {
+					return lit
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// addImport is an edit importing importPath, into the first import block
+// if there is one.
+func addImport(f *ast.File, offset func(token.Pos) int, importPath string) edit {
+	for _, decl := range f.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT && gen.Lparen.IsValid() {
+			return edit{offset(gen.Lparen) + 1, "\n" + strconv.Quote(importPath)}
+		}
+	}
+	return edit{offset(f.Name.End()), "\n\nimport " + strconv.Quote(importPath)}
+}
+
+func imports(f *ast.File, importPath string) bool {
+	for _, spec := range f.Imports {
+		if p, _ := strconv.Unquote(spec.Path.Value); p == importPath {
+			return true
+		}
+	}
+	return false
+}
+
+// packageImportPath returns the import path of the package in dir, which
+// must be inside the module in the current directory.
+func packageImportPath(dir string) (string, error) {
+	module, err := utils.ModulePath()
+	if err != nil {
+		return "", err
+	}
+	rel := filepath.ToSlash(filepath.Clean(dir))
+	if rel == "." {
+		return module, nil
+	}
+	if filepath.IsAbs(dir) || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("%s is outside the module", dir)
+	}
+	return module + "/" + rel, nil
+}
@@ -0,0 +1,147 @@
+package headers
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/goui-org/gouix/config"
+)
+
+var defaultMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE"}
+
+// Handler applies the response headers and CORS policy from the server
+// config to every response of next. Configured headers take precedence over
+// those set by next, so they also apply to proxied responses. The config is
+// read on each request, so changes to goui.yml apply without a restart.
+func Handler(cfg func() *config.ServerConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := cfg()
+		if c.CORS != nil && preflight(c.CORS, w, r) {
+			return
+		}
+		next.ServeHTTP(&writer{ResponseWriter: w, cfg: c, r: r}, r)
+	})
+}
+
+type writer struct {
+	http.ResponseWriter
+	cfg         *config.ServerConfig
+	r           *http.Request
+	wroteHeader bool
+}
+
+func (w *writer) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		apply(w.cfg, w.Header(), w.r)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *writer) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *writer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Hijack is needed by the websocket upgrade of the hot reload connection.
+func (w *writer) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	return h.Hijack()
+}
+
+func apply(cfg *config.ServerConfig, h http.Header, r *http.Request) {
+	if cfg.CrossOriginIsolated {
+		h.Set("Cross-Origin-Opener-Policy", "same-origin")
+		h.Set("Cross-Origin-Embedder-Policy", "require-corp")
+	}
+	for k, v := range cfg.Headers {
+		h.Set(k, v)
+	}
+	for _, rule := range cfg.PathHeaders {
+		if Match(rule.Path, r.URL.Path) {
+			for k, v := range rule.Headers {
+				h.Set(k, v)
+			}
+		}
+	}
+	if cfg.CORS != nil {
+		allowOrigin(cfg.CORS, h, r)
+	}
+}
+
+// Match reports whether the URL path matches pattern. A trailing "/**"
+// matches everything below a directory.
+func Match(pattern, urlPath string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return urlPath == prefix || strings.HasPrefix(urlPath, prefix+"/")
+	}
+	ok, _ := path.Match(pattern, urlPath)
+	return ok
+}
+
+func allowOrigin(cors *config.CORSConfig, h http.Header, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	allowed := false
+	for _, o := range cors.Origins {
+		if o == "*" || o == origin {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return false
+	}
+	h.Add("Vary", "Origin")
+	h.Set("Access-Control-Allow-Origin", origin)
+	if cors.Credentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if len(cors.ExposeHeaders) > 0 {
+		h.Set("Access-Control-Expose-Headers", strings.Join(cors.ExposeHeaders, ", "))
+	}
+	return true
+}
+
+// preflight answers CORS preflight requests, reporting whether it did.
+func preflight(cors *config.CORSConfig, w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+		return false
+	}
+	h := w.Header()
+	if !allowOrigin(cors, h, r) {
+		w.WriteHeader(http.StatusForbidden)
+		return true
+	}
+	methods := cors.Methods
+	if len(methods) == 0 {
+		methods = defaultMethods
+	}
+	h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	if len(cors.Headers) > 0 {
+		h.Set("Access-Control-Allow-Headers", strings.Join(cors.Headers, ", "))
+	} else if req := r.Header.Get("Access-Control-Request-Headers"); req != "" {
+		h.Set("Access-Control-Allow-Headers", req)
+	}
+	if cors.MaxAge > 0 {
+		h.Set("Access-Control-Max-Age", strconv.Itoa(cors.MaxAge))
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
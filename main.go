@@ -1,14 +1,22 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/goui-org/gouix/build"
 	"github.com/goui-org/gouix/config"
 	"github.com/goui-org/gouix/create"
+	"github.com/goui-org/gouix/editor"
+	"github.com/goui-org/gouix/generate"
+	"github.com/goui-org/gouix/preview"
 	"github.com/goui-org/gouix/serve"
+	"github.com/goui-org/gouix/show"
+	"github.com/goui-org/gouix/upgrade"
 
+	"github.com/mattn/go-isatty"
 	"github.com/urfave/cli/v2"
 )
 
@@ -20,8 +28,13 @@ func main() {
 			{
 				Name:  "serve",
 				Usage: "start develpoment server",
+				Flags: serve.Flags(),
 				Action: func(c *cli.Context) error {
-					return serve.Start(config.Get())
+					opts, err := serve.ParseOptions(c)
+					if err != nil {
+						return err
+					}
+					return serve.Start(config.Get(), opts)
 				},
 			},
 			{
@@ -31,11 +44,286 @@ func main() {
 					return build.New(config.Get()).Run()
 				},
 			},
+			{
+				Name:  "preview",
+				Usage: "serve the production build locally",
+				Action: func(c *cli.Context) error {
+					return preview.Start(config.Get())
+				},
+			},
+			{
+				Name:  "generate",
+				Usage: "generate components and pages from templates, see " + generate.TemplateDir,
+				Subcommands: []*cli.Command{
+					{
+						Name:      "component",
+						Usage:     "create a component, eg. components/UserCard",
+						ArgsUsage: "<path/Name>",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "css",
+								Usage: "also create a stylesheet imported from public/main.css",
+							},
+							&cli.BoolFlag{
+								Name:  "force",
+								Usage: "overwrite existing files",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							if c.NArg() != 1 {
+								return fmt.Errorf("usage: gouix generate component <path/Name>")
+							}
+							return generate.Component(config.Get(), c.Args().First(), &generate.ComponentOptions{
+								Options: generate.Options{Force: c.Bool("force")},
+								CSS:     c.Bool("css"),
+							})
+						},
+					},
+					{
+						Name:      "page",
+						Usage:     "create a page and register its route, eg. /settings",
+						ArgsUsage: "<route>",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "dir",
+								Usage: "directory of the page package (default: app/pages in the entry directory)",
+							},
+							&cli.StringFlag{
+								Name:  "router",
+								Usage: "file with the routes map (default: app/router.go in the entry directory)",
+							},
+							&cli.BoolFlag{
+								Name:  "force",
+								Usage: "overwrite existing files",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							if c.NArg() != 1 {
+								return fmt.Errorf("usage: gouix generate page <route>")
+							}
+							return generate.Page(config.Get(), c.Args().First(), &generate.PageOptions{
+								Options: generate.Options{Force: c.Bool("force")},
+								Dir:     c.String("dir"),
+								Router:  c.String("router"),
+							})
+						},
+					},
+				},
+			},
+			{
+				Name:  "config",
+				Usage: "inspect goui.yml",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "show",
+						Usage: "print the settings in effect, where they come from and the commands a build runs",
+						Description: "Settings come from the defaults, goui.yml, the flags of serve, which\n" +
+							"config show accepts too, and the DEBUG environment variable. There are\n" +
+							"no profiles: one goui.yml configures every command.",
+						Flags: serve.Flags(),
+						Action: func(c *cli.Context) error {
+							opts, err := serve.ParseOptions(c)
+							if err != nil {
+								return err
+							}
+							yml, err := os.ReadFile("goui.yml")
+							if err != nil {
+								return err
+							}
+							return show.Config(os.Stdout, yml, &show.Options{Serve: opts, Flags: c.IsSet})
+						},
+					},
+					{
+						Name:  "schema",
+						Usage: "print the JSON Schema of goui.yml",
+						Action: func(c *cli.Context) error {
+							_, err := os.Stdout.Write(config.JSONSchemaBytes())
+							return err
+						},
+					},
+					{
+						Name:      "validate",
+						Usage:     "check goui.yml against its schema",
+						ArgsUsage: "[file]",
+						Action: func(c *cli.Context) error {
+							file := "goui.yml"
+							if c.NArg() > 0 {
+								file = c.Args().First()
+							}
+							b, err := os.ReadFile(file)
+							if err != nil {
+								return err
+							}
+							problems, err := config.Validate(b)
+							if err != nil {
+								return err
+							}
+							for _, p := range problems {
+								fmt.Printf("%s:%s\n", file, p)
+							}
+							if len(problems) > 0 {
+								return cli.Exit(fmt.Sprintf("%s has %d problems", file, len(problems)), 1)
+							}
+							fmt.Printf("%s is valid\n", file)
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:  "editor-config",
+				Usage: "write editor settings for gopls, tasks to run gouix and the goui.yml schema",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "editor",
+						Value: cli.NewStringSlice("vscode"),
+						Usage: "editor to configure: " + strings.Join(editor.Editors(), ", "),
+					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "overwrite existing settings that can't be merged",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					cfg := config.Get()
+					for _, name := range c.StringSlice("editor") {
+						if err := editor.Write(cfg, name, &editor.Options{Force: c.Bool("force")}); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "upgrade",
+				Usage: "update goui, goui.yml and unmodified project files to this gouix",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "goui-version",
+						Value: "latest",
+						Usage: "goui version to require: latest or vX.Y.Z",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "report the changes without making them",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return upgrade.Upgrade(&upgrade.Options{
+						GouiVersion: c.String("goui-version"),
+						DryRun:      c.Bool("dry-run"),
+					})
+				},
+			},
+			{
+				Name:  "init",
+				Usage: "add goui to the Go module in the current directory",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "dir",
+						Value: create.DefaultEntry,
+						Usage: "directory to add the main package to",
+					},
+					&cli.StringFlag{
+						Name:  "goui-version",
+						Value: "latest",
+						Usage: "goui version to require: latest or vX.Y.Z",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "list the changes without making them",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return create.Init(&create.InitOptions{
+						Entry:       c.String("dir"),
+						GouiVersion: c.String("goui-version"),
+						DryRun:      c.Bool("dry-run"),
+					})
+				},
+			},
 			{
 				Name:  "create",
 				Usage: "create a new goui application",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "template",
+						Value: create.DefaultTemplate,
+						Usage: "template to start from: " + strings.Join(create.Templates(), ", ") + " or the path of a template directory",
+					},
+					&cli.StringSliceFlag{
+						Name:  "var",
+						Usage: "set a template variable as key=value",
+					},
+					&cli.StringFlag{
+						Name:  "module",
+						Usage: "path of the new Go module, eg. github.com/acme/my-app (default: the project name)",
+					},
+					&cli.StringFlag{
+						Name:  "goui-version",
+						Value: "latest",
+						Usage: "goui version to require: latest or vX.Y.Z",
+					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "create the project in a non-empty directory, overwriting existing files",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "list the files that would be created without writing anything",
+					},
+					&cli.BoolFlag{
+						Name:  "wasm-opt",
+						Usage: "optimize builds with wasm-opt",
+					},
+					&cli.BoolFlag{
+						Name:  "git",
+						Usage: "initialize a git repository",
+					},
+					&cli.BoolFlag{
+						Name:  "editor-settings",
+						Value: true,
+						Usage: "write VS Code settings",
+					},
+					&cli.BoolFlag{
+						Name:  "run-post-create",
+						Usage: "run the template's post_create commands without asking",
+					},
+					&cli.BoolFlag{
+						Name:  "no-prompt",
+						Usage: "never ask questions, even in a terminal",
+					},
+				},
 				Action: func(c *cli.Context) error {
-					return create.Create(c.Args().First())
+					vars := make(map[string]string)
+					for _, v := range c.StringSlice("var") {
+						key, value, ok := strings.Cut(v, "=")
+						if !ok {
+							return fmt.Errorf("invalid template variable %q, expected key=value", v)
+						}
+						vars[key] = value
+					}
+					opts := &create.Options{
+						Template:           c.String("template"),
+						Vars:               vars,
+						Module:             c.String("module"),
+						GouiVersion:        c.String("goui-version"),
+						Force:              c.Bool("force"),
+						DryRun:             c.Bool("dry-run"),
+						WASMOpt:            c.Bool("wasm-opt"),
+						Git:                c.Bool("git"),
+						SkipEditorSettings: !c.Bool("editor-settings"),
+						RunPostCreate:      c.Bool("run-post-create"),
+					}
+					name := c.Args().First()
+					if !c.Bool("no-prompt") && isatty.IsTerminal(os.Stdin.Fd()) {
+						var err error
+						name, err = create.Prompt(create.NewPrompter(os.Stdin, os.Stdout), name, opts, c.IsSet)
+						if err != nil {
+							return err
+						}
+					}
+					return create.Create(name, opts)
 				},
 			},
 		},
@@ -0,0 +1,292 @@
+package mocks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/goui-org/gouix/config"
+)
+
+// Dir is the directory mock responses are read from by convention.
+const Dir = "mocks"
+
+// fileName matches mock files named METHOD[.STATUS].ext[.tmpl], eg.
+// GET.json, POST.201.json or GET.json.tmpl.
+var fileName = regexp.MustCompile(`^(GET|HEAD|POST|PUT|PATCH|DELETE|OPTIONS|ANY)(?:\.(\d{3}))?(\.[^.]+)?(\.tmpl)?$`)
+
+// Route is a mocked response for requests matching a method and path.
+type Route struct {
+	Method   string
+	Path     string
+	Status   int
+	Delay    time.Duration
+	Headers  map[string]string
+	segments []string
+	body     []byte
+	tmpl     *template.Template
+}
+
+// Set is an ordered list of routes. The first route matching a request
+// answers it.
+type Set struct {
+	routes []*Route
+}
+
+// Data is passed to templated responses.
+type Data struct {
+	Method string
+	Path   string
+	Params map[string]string
+	Query  url.Values
+	Header http.Header
+	// Body is the request body, decoded if it is JSON
+	Body any
+}
+
+// Load reads the routes declared in goui.yml followed by those in the
+// mocks directory. Routes in the directory with fewer path parameters come
+// first, so /users/me wins over /users/[id].
+func Load(routes []*config.MockConfig) (*Set, error) {
+	fail := func(err error) (*Set, error) {
+		return nil, fmt.Errorf("mocks.Load: %w", err)
+	}
+	s := &Set{}
+	for _, rc := range routes {
+		r, err := fromConfig(rc)
+		if err != nil {
+			return fail(err)
+		}
+		s.routes = append(s.routes, r)
+	}
+	dirRoutes, err := fromDir(Dir)
+	if err != nil {
+		return fail(err)
+	}
+	s.routes = append(s.routes, dirRoutes...)
+	return s, nil
+}
+
+func fromConfig(rc *config.MockConfig) (*Route, error) {
+	r := &Route{
+		Method:  strings.ToUpper(rc.Method),
+		Path:    rc.Path,
+		Status:  rc.Status,
+		Delay:   rc.Delay,
+		Headers: rc.Headers,
+	}
+	if r.Method == "" {
+		r.Method = "ANY"
+	}
+	if r.Status == 0 {
+		r.Status = http.StatusOK
+	}
+	r.segments = split(rc.Path)
+	body := []byte(rc.Body)
+	name := "body"
+	if rc.File != "" {
+		b, err := os.ReadFile(filepath.Join(Dir, rc.File))
+		if err != nil {
+			return nil, err
+		}
+		body = b
+		name = rc.File
+		if r.Headers["Content-Type"] == "" {
+			r.setContentType(strings.TrimSuffix(rc.File, ".tmpl"))
+		}
+	}
+	if rc.Template || strings.HasSuffix(rc.File, ".tmpl") {
+		t, err := template.New(name).Parse(string(body))
+		if err != nil {
+			return nil, err
+		}
+		r.tmpl = t
+	} else {
+		r.body = body
+	}
+	return r, nil
+}
+
+func fromDir(dir string) ([]*Route, error) {
+	var routes []*Route
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		m := fileName.FindStringSubmatch(d.Name())
+		if m == nil {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		urlPath := "/"
+		if rel != "." {
+			urlPath += filepath.ToSlash(rel)
+		}
+		rc := &config.MockConfig{
+			Method: m[1],
+			Path:   dirPattern(urlPath),
+			File:   strings.TrimPrefix(filepath.ToSlash(p), dir+"/"),
+		}
+		if m[2] != "" {
+			rc.Status, _ = strconv.Atoi(m[2])
+		}
+		r, err := fromConfig(rc)
+		if err != nil {
+			return err
+		}
+		routes = append(routes, r)
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return params(routes[i].segments) < params(routes[j].segments)
+	})
+	return routes, err
+}
+
+// dirPattern turns [name] and [...name] directories into :name and *.
+func dirPattern(p string) string {
+	segments := split(p)
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, "[...") && strings.HasSuffix(seg, "]") {
+			segments[i] = "*"
+		} else if strings.HasPrefix(seg, "[") && strings.HasSuffix(seg, "]") {
+			segments[i] = ":" + seg[1:len(seg)-1]
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+func params(segments []string) int {
+	n := 0
+	for _, seg := range segments {
+		if seg == "*" {
+			n += 100
+		} else if strings.HasPrefix(seg, ":") {
+			n++
+		}
+	}
+	return n
+}
+
+func split(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+func (r *Route) setContentType(name string) {
+	ty := mime.TypeByExtension(path.Ext(name))
+	if ty == "" {
+		return
+	}
+	if r.Headers == nil {
+		r.Headers = make(map[string]string)
+	}
+	r.Headers["Content-Type"] = ty
+}
+
+// match reports whether the route answers req, returning the values of
+// its path parameters. GET routes answer HEAD requests too.
+func (r *Route) match(req *http.Request) (map[string]string, bool) {
+	if !r.matchMethod(req.Method) {
+		return nil, false
+	}
+	parts := split(req.URL.Path)
+	params := make(map[string]string)
+	for i, seg := range r.segments {
+		if seg == "*" {
+			params["*"] = strings.Join(parts[i:], "/")
+			return params, true
+		}
+		if i >= len(parts) {
+			return nil, false
+		}
+		if strings.HasPrefix(seg, ":") {
+			params[seg[1:]] = parts[i]
+		} else if seg != parts[i] {
+			return nil, false
+		}
+	}
+	return params, len(parts) == len(r.segments)
+}
+
+func (r *Route) matchMethod(method string) bool {
+	return r.Method == "ANY" || r.Method == method || r.Method == http.MethodGet && method == http.MethodHead
+}
+
+// Serve answers req with the first matching route, reporting whether
+// there was one.
+func (s *Set) Serve(w http.ResponseWriter, req *http.Request) bool {
+	if s == nil {
+		return false
+	}
+	for _, r := range s.routes {
+		params, ok := r.match(req)
+		if !ok {
+			continue
+		}
+		r.serve(w, req, params)
+		return true
+	}
+	return false
+}
+
+func (r *Route) serve(w http.ResponseWriter, req *http.Request, params map[string]string) {
+	body := r.body
+	if r.tmpl != nil {
+		data := &Data{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Params: params,
+			Query:  req.URL.Query(),
+			Header: req.Header,
+		}
+		if b, err := io.ReadAll(req.Body); err == nil && len(b) > 0 {
+			if json.Unmarshal(b, &data.Body) != nil {
+				data.Body = string(b)
+			}
+		}
+		var buf bytes.Buffer
+		if err := r.tmpl.Execute(&buf, data); err != nil {
+			http.Error(w, fmt.Sprintf("mocks: %s", err), http.StatusInternalServerError)
+			return
+		}
+		body = buf.Bytes()
+	}
+	if r.Delay > 0 {
+		select {
+		case <-time.After(r.Delay):
+		case <-req.Context().Done():
+			return
+		}
+	}
+	for k, v := range r.Headers {
+		w.Header().Set(k, v)
+	}
+	w.WriteHeader(r.Status)
+	if req.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goui-org/gouix/config"
+)
+
+func TestServe(t *testing.T) {
+	var routes []*Route
+	for _, rc := range []*config.MockConfig{
+		{Method: "HEAD", Path: "/api/status", Status: http.StatusNoContent},
+		{Method: "GET", Path: "/api/status", Body: "ok"},
+		{Method: "GET", Path: "/api/users/:id", Body: "{{.Params.id}}", Template: true},
+		{Method: "POST", Path: "/api/users", Status: http.StatusCreated, Body: "created"},
+	} {
+		r, err := fromConfig(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		routes = append(routes, r)
+	}
+	set := &Set{routes: routes}
+	tests := []struct {
+		method, path string
+		served       bool
+		status       int
+		body         string
+	}{
+		{"GET", "/api/status", true, http.StatusOK, "ok"},
+		// a HEAD route comes first, and GET routes answer HEAD otherwise
+		{"HEAD", "/api/status", true, http.StatusNoContent, ""},
+		{"GET", "/api/users/7", true, http.StatusOK, "7"},
+		{"HEAD", "/api/users/7", true, http.StatusOK, ""},
+		{"POST", "/api/users", true, http.StatusCreated, "created"},
+		{"HEAD", "/api/users", false, 0, ""},
+		{"DELETE", "/api/users/7", false, 0, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		served := set.Serve(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if served != tt.served {
+			t.Errorf("%s %s: served = %v, want %v", tt.method, tt.path, served, tt.served)
+			continue
+		}
+		if served && (w.Code != tt.status || w.Body.String() != tt.body) {
+			t.Errorf("%s %s = %d %q, want %d %q", tt.method, tt.path, w.Code, w.Body.String(), tt.status, tt.body)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package preview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/goui-org/gouix/certs"
+	"github.com/goui-org/gouix/config"
+	"github.com/goui-org/gouix/headers"
+
+	"github.com/fatih/color"
+)
+
+// Start serves the production build, with the response headers configured
+// for the development server, until interrupted.
+func Start(cfg *config.Config) error {
+	fail := func(err error) error {
+		return fmt.Errorf("preview.Start: %w", err)
+	}
+	// gouix build writes production builds here, even when DEBUG is set
+	// in the environment
+	const dir = "build"
+	if _, err := os.Stat(dir); err != nil {
+		return fail(fmt.Errorf("%w, run gouix build first", err))
+	}
+	srv := &http.Server{
+		Addr:    cfg.Server.Addr(),
+		Handler: headers.Handler(func() *config.ServerConfig { return cfg.Server }, http.FileServer(http.Dir(dir))),
+	}
+	if cfg.Server.HTTPS {
+		tlsConfig, err := certs.TLSConfig(cfg.Server)
+		if err != nil {
+			return fail(err)
+		}
+		srv.TLSConfig = tlsConfig
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errc := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			errc <- srv.ListenAndServeTLS("", "")
+		} else {
+			errc <- srv.ListenAndServe()
+		}
+	}()
+	color.Green("Previewing %s at %s\n\n", dir, cfg.Server.URL())
+	fmt.Printf("Press Ctrl+C to stop\n")
+	select {
+	case err := <-errc:
+		return fail(err)
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fail(err)
+	}
+	return nil
+}
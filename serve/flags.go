@@ -0,0 +1,41 @@
+package serve
+
+import (
+	"github.com/goui-org/gouix/server"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Flags are the flags of gouix serve, which gouix config show accepts too.
+func Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "mock",
+			Usage: "answer requests from the mocks directory before proxying",
+		},
+		&cli.BoolFlag{
+			Name:  "log",
+			Usage: "print every request served",
+		},
+		&cli.StringFlag{
+			Name:  "throttle",
+			Usage: "simulate a slow network: slow-3g, 3g, slow-4g, 4g or <kbps>,<latency>",
+		},
+	}
+}
+
+// ParseOptions returns the server options set by Flags.
+func ParseOptions(c *cli.Context) (*server.Options, error) {
+	opts := &server.Options{
+		Mock:      c.Bool("mock"),
+		AccessLog: c.Bool("log"),
+	}
+	if c.IsSet("throttle") {
+		t, err := server.ParseThrottle(c.String("throttle"))
+		if err != nil {
+			return nil, err
+		}
+		opts.Throttle = t
+	}
+	return opts, nil
+}
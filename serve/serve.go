@@ -1,26 +1,26 @@
 package serve
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
+	"syscall"
 
 	"github.com/goui-org/gouix/config"
 	"github.com/goui-org/gouix/server"
 )
 
-func Start(cfg *config.Config) error {
+func Start(cfg *config.Config, opts *server.Options) error {
 	os.Setenv("DEBUG", "true")
-	server, err := server.New(cfg)
+	server, err := server.New(cfg, opts)
 	if err != nil {
 		return fmt.Errorf("serve.Start: %w", err)
 	}
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-	go func() {
-		<-c
-		server.Shutdown()
-		os.Exit(0)
-	}()
-	return server.Run()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := server.Run(ctx); err != nil {
+		return fmt.Errorf("serve.Start: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/twharmon/gouid"
+)
+
+const (
+	writeWait    = time.Second * 10
+	pongWait     = time.Second * 60
+	pingInterval = pongWait * 9 / 10
+	sendBuffer   = 64
+)
+
+// client is a single browser tab connected to the /hot websocket.
+type client struct {
+	id       string
+	conn     *websocket.Conn
+	send     chan []byte
+	done     chan struct{}
+	stopping chan []byte
+	once     sync.Once
+	mu       sync.Mutex
+	loaded   bool
+	url      string
+	lastSeen time.Time
+	pending  [][]byte
+}
+
+func newClient(conn *websocket.Conn) *client {
+	return &client{
+		id:       gouid.String(6, gouid.Secure32Char),
+		conn:     conn,
+		send:     make(chan []byte, sendBuffer),
+		done:     make(chan struct{}),
+		stopping: make(chan []byte, 1),
+		lastSeen: time.Now(),
+	}
+}
+
+// enqueue delivers b to the client without blocking. Messages sent before
+// the tab reports it has loaded are held until it does. It returns false
+// if the client can't keep up and should be dropped.
+func (c *client) enqueue(b []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.loaded {
+		if len(c.pending) == sendBuffer {
+			c.pending = c.pending[1:]
+		}
+		c.pending = append(c.pending, b)
+		return true
+	}
+	return c.push(b)
+}
+
+func (c *client) push(b []byte) bool {
+	select {
+	case <-c.done:
+		return false
+	case c.send <- b:
+		return true
+	default:
+		return false
+	}
+}
+
+// markLoaded flushes any messages held while the tab was loading.
+func (c *client) markLoaded(url string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loaded = true
+	c.url = url
+	for _, b := range c.pending {
+		if !c.push(b) {
+			return false
+		}
+	}
+	c.pending = nil
+	return true
+}
+
+func (c *client) seen() {
+	c.mu.Lock()
+	c.lastSeen = time.Now()
+	c.mu.Unlock()
+}
+
+// stale reports whether the browser hasn't answered for longer than
+// pongWait, which happens when a suspended tab stops reading.
+func (c *client) stale() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Since(c.lastSeen) > pongWait
+}
+
+// stop makes the write pump send b as the last message, followed by a
+// close frame.
+func (c *client) stop(b []byte) {
+	select {
+	case c.stopping <- b:
+	default:
+	}
+}
+
+func (c *client) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// writePump owns all writes to the connection. It sends queued messages
+// and pings the browser, dropping it when it stopped answering.
+func (c *client) writePump(ping func() []byte) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	defer c.close()
+	for {
+		select {
+		case <-c.done:
+			return
+		case b := <-c.stopping:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.conn.WriteMessage(websocket.TextMessage, b)
+			c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server stopped"))
+			return
+		case b := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, b); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if c.stale() {
+				return
+			}
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, ping()); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+var levelColors = map[string]*color.Color{
+	LevelError:     color.New(color.FgRed),
+	LevelWarn:      color.New(color.FgYellow),
+	LevelPanic:     color.New(color.FgMagenta, color.Bold),
+	LevelException: color.New(color.FgRed, color.Bold),
+	LevelRejection: color.New(color.FgRed, color.Bold),
+}
+
+// console prints output forwarded from a browser tab to the terminal.
+// Uncaught exceptions are sent back to the tab with their stack traces
+// symbolized so they can be shown in the error overlay.
+func (s *Server) console(c *client, msg *Message) {
+	s.mu.Lock()
+	table := s.symbols
+	s.mu.Unlock()
+	msg.Text = table.Symbolize(msg.Text)
+	msg.Stack = table.Symbolize(msg.Stack)
+	printConsole(c, msg)
+	if msg.Level != LevelException && msg.Level != LevelRejection {
+		return
+	}
+	reply := newMessage(MessageRuntimeError)
+	reply.Text = msg.Text
+	reply.Stack = msg.Stack
+	if b, err := reply.encode(); err == nil {
+		c.enqueue(b)
+	}
+}
+
+func printConsole(c *client, msg *Message) {
+	c.mu.Lock()
+	tab := c.id
+	if c.url != "" {
+		tab += " " + c.url
+	}
+	c.mu.Unlock()
+	col, ok := levelColors[msg.Level]
+	if !ok {
+		col = color.New(color.Reset)
+	}
+	prefix := color.New(color.Faint).Sprintf("[tab %s]", tab)
+	text := strings.TrimRight(msg.Text, "\n")
+	fmt.Printf("%s %s %s\n", prefix, col.Sprintf("%s:", msg.Level), text)
+	if msg.Stack != "" {
+		for _, line := range strings.Split(strings.TrimRight(msg.Stack, "\n"), "\n") {
+			fmt.Printf("%s     %s\n", prefix, line)
+		}
+	}
+}
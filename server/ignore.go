@@ -0,0 +1,198 @@
+package server
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/twharmon/slices"
+)
+
+// defaultIgnore lists directories that never contain sources for the app.
+var defaultIgnore = []string{".git/", "node_modules/", ".vscode/", ".idea/", "/build/"}
+
+type ignorePattern struct {
+	segments []string
+	dirOnly  bool
+	anchored bool
+	// negated patterns re-include paths an earlier pattern ignored
+	negated bool
+}
+
+// ignoreRoot is a module outside the project, matched against the
+// defaults and its own .gitignore.
+type ignoreRoot struct {
+	dir      string
+	patterns []ignorePattern
+}
+
+// ignorer matches paths relative to the project root, or to one of the
+// extra roots outside it, against gitignore style glob patterns. As in
+// git, the last matching pattern wins, and a path can't be re-included
+// once a directory containing it is ignored.
+type ignorer struct {
+	mu sync.Mutex
+	// patterns apply to the project, source is what they were parsed from
+	patterns []ignorePattern
+	source   []string
+	roots    []*ignoreRoot
+}
+
+func newIgnorer(patterns []string) *ignorer {
+	ig := &ignorer{}
+	ig.load(patterns)
+	return ig
+}
+
+// load replaces the project patterns with the defaults, patterns and those
+// in .gitignore, reporting whether they changed.
+func (ig *ignorer) load(patterns []string) bool {
+	source := slices.Concat(defaultIgnore, patterns, readGitIgnore(".gitignore"))
+	ig.mu.Lock()
+	defer ig.mu.Unlock()
+	if strings.Join(source, "\n") == strings.Join(ig.source, "\n") {
+		return false
+	}
+	ig.source = source
+	ig.patterns = parsePatterns(source)
+	return true
+}
+
+func readGitIgnore(name string) []string {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var patterns []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns
+}
+
+func parsePatterns(source []string) []ignorePattern {
+	var patterns []ignorePattern
+	for _, s := range source {
+		if p, ok := parsePattern(s); ok {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+func parsePattern(pattern string) (ignorePattern, bool) {
+	var p ignorePattern
+	pattern = filepath.ToSlash(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return p, false
+	}
+	if strings.HasPrefix(pattern, "!") {
+		p.negated = true
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		p.dirOnly = true
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	if strings.Contains(pattern, "/") {
+		p.anchored = true
+		pattern = strings.TrimPrefix(pattern, "/")
+	}
+	p.segments = strings.Split(pattern, "/")
+	return p, true
+}
+
+// addRoot adds a module outside the project, reading its .gitignore.
+func (ig *ignorer) addRoot(dir string) {
+	dir = filepath.Clean(dir)
+	patterns := parsePatterns(slices.Concat(defaultIgnore, readGitIgnore(filepath.Join(dir, ".gitignore"))))
+	ig.mu.Lock()
+	ig.roots = append(ig.roots, &ignoreRoot{dir: dir, patterns: patterns})
+	ig.mu.Unlock()
+}
+
+// rel returns name relative to the root containing it, and the patterns
+// that apply there.
+func (ig *ignorer) rel(name string) (string, []ignorePattern, bool) {
+	ig.mu.Lock()
+	defer ig.mu.Unlock()
+	if !filepath.IsAbs(name) {
+		return filepath.Clean(name), ig.patterns, true
+	}
+	for _, root := range ig.roots {
+		if rel, err := filepath.Rel(root.dir, name); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel, root.patterns, true
+		}
+	}
+	return "", nil, false
+}
+
+// match reports whether name, or any directory containing it, is ignored.
+func (ig *ignorer) match(name string, isDir bool) bool {
+	rel, patterns, ok := ig.rel(name)
+	if !ok {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." || strings.HasPrefix(rel, "../") {
+		return false
+	}
+	parts := strings.Split(rel, "/")
+	for i := range parts {
+		dir := isDir || i < len(parts)-1
+		ignored := false
+		for _, p := range patterns {
+			if ignored != p.negated || p.dirOnly && !dir {
+				continue
+			}
+			if p.matches(parts[:i+1]) {
+				ignored = !p.negated
+			}
+		}
+		if ignored {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the pattern matches the path made of parts.
+func (p *ignorePattern) matches(parts []string) bool {
+	if p.anchored {
+		return matchSegments(p.segments, parts)
+	}
+	ok, _ := path.Match(p.segments[0], parts[len(parts)-1])
+	return ok
+}
+
+// matchSegments matches path segments against pattern segments, where a
+// "**" segment matches any number of path segments.
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
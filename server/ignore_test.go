@@ -0,0 +1,88 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnorerMatch(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		name     string
+		isDir    bool
+		want     bool
+	}{
+		// unanchored patterns match at any depth
+		{[]string{"build"}, "build", true, true},
+		{[]string{"build"}, "internal/build", true, true},
+		{[]string{"build"}, "internal/build/main.go", false, true},
+		{[]string{"*.log"}, "logs/today.log", false, true},
+		{[]string{"*.log"}, "today.go", false, false},
+
+		// a leading or inner slash anchors the pattern to the root
+		{[]string{"/build"}, "build", true, true},
+		{[]string{"/build"}, "internal/build", true, false},
+		{[]string{"src/gen"}, "src/gen", true, true},
+		{[]string{"src/gen"}, "lib/src/gen", true, false},
+
+		// a trailing slash only matches directories
+		{[]string{"dist/"}, "dist", true, true},
+		{[]string{"dist/"}, "dist", false, false},
+		{[]string{"dist/"}, "dist/app.js", false, true},
+		{[]string{"dist"}, "dist", false, true},
+
+		// ** matches any number of directories
+		{[]string{"**/testdata"}, "testdata", true, true},
+		{[]string{"**/testdata"}, "a/b/testdata", true, true},
+		{[]string{"src/**/gen"}, "src/gen", true, true},
+		{[]string{"src/**/gen"}, "src/a/b/gen", true, true},
+		{[]string{"src/**/gen"}, "lib/a/gen", true, false},
+		{[]string{"/a/**"}, "a/b/c.go", false, true},
+
+		// negation re-includes, and the last matching pattern wins
+		{[]string{"*.go", "!main.go"}, "src/main.go", false, false},
+		{[]string{"*.go", "!main.go"}, "src/app.go", false, true},
+		{[]string{"!main.go", "*.go"}, "main.go", false, true},
+		{[]string{"gen/", "!gen/"}, "gen", true, false},
+		// but not below an ignored directory
+		{[]string{"gen/", "!gen/keep.go"}, "gen/keep.go", false, true},
+		{[]string{"gen/*", "!gen/keep.go"}, "gen/keep.go", false, false},
+
+		{[]string{"build"}, ".", true, false},
+	}
+	for _, tt := range tests {
+		ig := &ignorer{patterns: parsePatterns(tt.patterns)}
+		if got := ig.match(tt.name, tt.isDir); got != tt.want {
+			t.Errorf("%q.match(%q, %v) = %v, want %v", tt.patterns, tt.name, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestIgnorerRoots(t *testing.T) {
+	mod := t.TempDir()
+	if err := os.WriteFile(filepath.Join(mod, ".gitignore"), []byte("/gen/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ig := &ignorer{patterns: parsePatterns([]string{"/vendor/"})}
+	ig.addRoot(mod)
+	tests := []struct {
+		name string
+		want bool
+	}{
+		// the module's own .gitignore and the defaults apply in it
+		{filepath.Join(mod, "gen"), true},
+		{filepath.Join(mod, ".git"), true},
+		// the project's patterns don't
+		{filepath.Join(mod, "vendor"), false},
+		{"vendor", true},
+		{"gen", false},
+		// paths outside the roots are never ignored
+		{filepath.Join(filepath.Dir(mod), "elsewhere", "gen"), false},
+	}
+	for _, tt := range tests {
+		if got := ig.match(tt.name, true); got != tt.want {
+			t.Errorf("match(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
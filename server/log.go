@@ -0,0 +1,113 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/goui-org/gouix/utils"
+
+	"github.com/fatih/color"
+)
+
+// Sources a response can be served from, shown in the access log.
+const (
+	sourceBuild = "build"
+	sourceProxy = "proxy"
+	sourceMock  = "mock"
+)
+
+type logEntryKey struct{}
+
+type logEntry struct {
+	source string
+}
+
+// setSource records where the response to r came from.
+func setSource(r *http.Request, source string) {
+	if e, ok := r.Context().Value(logEntryKey{}).(*logEntry); ok {
+		e.source = source
+	}
+}
+
+type recorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *recorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+func (w *recorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *recorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	w.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
+var statusColors = []struct {
+	min   int
+	color *color.Color
+}{
+	{500, color.New(color.FgRed)},
+	{400, color.New(color.FgYellow)},
+	{300, color.New(color.FgCyan)},
+	{0, color.New(color.FgGreen)},
+}
+
+// logRequests prints a line for every request when the access log is on.
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.opts.AccessLog && !s.serverConfig().AccessLog {
+			next.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+		entry := &logEntry{}
+		rec := &recorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), logEntryKey{}, entry)))
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		col := statusColors[len(statusColors)-1].color
+		for _, sc := range statusColors {
+			if status >= sc.min {
+				col = sc.color
+				break
+			}
+		}
+		fmt.Printf(
+			"%s %s %s %s %s %s\n",
+			utils.PadRight(r.Method, 7),
+			col.Sprint(status),
+			utils.PadLeft(utils.FormatFileSize(rec.bytes), 9),
+			utils.PadLeft(time.Since(start).Round(time.Microsecond*100).String(), 9),
+			utils.PadRight(entry.source, 5),
+			r.URL.RequestURI(),
+		)
+	})
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ProtocolVersion is the version of the hot reload protocol spoken over
+// the /hot websocket. It is bumped whenever a message changes shape.
+const ProtocolVersion = 1
+
+type MessageType string
+
+const (
+	// MessageReload tells the browser to reload the page.
+	MessageReload MessageType = "reload"
+	// MessageSwap tells the browser to swap in the module at URL without
+	// reloading the page.
+	MessageSwap MessageType = "swap"
+	// MessageCSS tells the browser to refresh the stylesheets in Paths.
+	MessageCSS MessageType = "css"
+	// MessageError carries a build error to display in the overlay.
+	MessageError MessageType = "error"
+	// MessageBuilding is sent when a build starts.
+	MessageBuilding MessageType = "building"
+	// MessageBuilt is sent when a build succeeds.
+	MessageBuilt MessageType = "built"
+	// MessageStopped is sent when the server shuts down.
+	MessageStopped MessageType = "stopped"
+	// MessagePing is a heartbeat sent periodically by the server.
+	MessagePing MessageType = "ping"
+	// MessageRuntimeError carries an uncaught exception from the browser
+	// back to it with its stack trace symbolized.
+	MessageRuntimeError MessageType = "runtime_error"
+	// MessageLoaded is sent by the browser once the page has loaded.
+	MessageLoaded MessageType = "loaded"
+	// MessageConsole is sent by the browser to forward console output,
+	// uncaught exceptions and Go runtime panics to the terminal.
+	MessageConsole MessageType = "console"
+)
+
+// Console levels reported by the browser in a MessageConsole.
+const (
+	LevelError     = "error"
+	LevelWarn      = "warn"
+	LevelPanic     = "panic"
+	LevelException = "exception"
+	LevelRejection = "rejection"
+)
+
+type Message struct {
+	Version  int         `json:"v"`
+	Type     MessageType `json:"type"`
+	Error    string      `json:"error,omitempty"`
+	Paths    []string    `json:"paths,omitempty"`
+	Duration string      `json:"duration,omitempty"`
+	URL      string      `json:"url,omitempty"`
+	Level    string      `json:"level,omitempty"`
+	Text     string      `json:"text,omitempty"`
+	Stack    string      `json:"stack,omitempty"`
+}
+
+func newMessage(ty MessageType) *Message {
+	return &Message{Version: ProtocolVersion, Type: ty}
+}
+
+func (m *Message) encode() ([]byte, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("server.Message.encode: %w", err)
+	}
+	return b, nil
+}
+
+func decodeMessage(b []byte) (*Message, error) {
+	var m Message
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("server.decodeMessage: %w", err)
+	}
+	if m.Version != ProtocolVersion {
+		return nil, fmt.Errorf("server.decodeMessage: unsupported protocol version %d", m.Version)
+	}
+	return &m, nil
+}
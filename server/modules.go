@@ -0,0 +1,105 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type goModJSON struct {
+	Replace []struct {
+		New struct {
+			Path    string
+			Version string
+		}
+	}
+}
+
+type goWorkJSON struct {
+	Use []struct {
+		DiskPath string
+	}
+}
+
+// localModules returns the absolute directories of modules the project
+// depends on from the local disk, through replace directives in go.mod or
+// use directives in go.work. The project itself is not included.
+func localModules() ([]string, error) {
+	fail := func(err error) ([]string, error) {
+		return nil, fmt.Errorf("devserver.localModules: %w", err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return fail(err)
+	}
+	var dirs []string
+	add := func(base, dir string) {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(base, dir)
+		}
+		dir = filepath.Clean(dir)
+		if dir == wd {
+			return
+		}
+		for _, d := range dirs {
+			if d == dir {
+				return
+			}
+		}
+		dirs = append(dirs, dir)
+	}
+	out, err := exec.Command("go", "mod", "edit", "-json").Output()
+	if err != nil {
+		return fail(err)
+	}
+	var mod goModJSON
+	if err := json.Unmarshal(out, &mod); err != nil {
+		return fail(err)
+	}
+	for _, r := range mod.Replace {
+		if r.New.Version == "" && isLocalPath(r.New.Path) {
+			add(wd, r.New.Path)
+		}
+	}
+	out, err = exec.Command("go", "env", "GOWORK").Output()
+	if err != nil {
+		return fail(err)
+	}
+	work := strings.TrimSpace(string(out))
+	if work == "" || work == "off" {
+		return dirs, nil
+	}
+	out, err = exec.Command("go", "work", "edit", "-json", work).Output()
+	if err != nil {
+		return fail(err)
+	}
+	var ws goWorkJSON
+	if err := json.Unmarshal(out, &ws); err != nil {
+		return fail(err)
+	}
+	for _, u := range ws.Use {
+		add(filepath.Dir(work), u.DiskPath)
+	}
+	return dirs, nil
+}
+
+// isLocalPath reports whether a replacement path refers to a directory
+// rather than a module path, following the rules of the go command.
+func isLocalPath(p string) bool {
+	return filepath.IsAbs(p) || p == "." || p == ".." ||
+		strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") ||
+		strings.HasPrefix(p, `.\`) || strings.HasPrefix(p, `..\`)
+}
+
+// isModuleSource reports whether a change to name in a dependency affects
+// the build.
+func isModuleSource(name string) bool {
+	switch filepath.Base(name) {
+	case "go.mod", "go.sum":
+		return true
+	}
+	return filepath.Ext(name) == ".go"
+}
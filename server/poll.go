@@ -0,0 +1,176 @@
+package server
+
+import (
+	"errors"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+	isDir   bool
+}
+
+// pollWatcher detects changes by periodically scanning the watched
+// directories and comparing modification times and sizes. It works where
+// filesystem notifications don't, such as bind mounts and network shares.
+type pollWatcher struct {
+	mu       sync.Mutex
+	dirs     map[string]map[string]fileState
+	events   chan fsnotify.Event
+	errors   chan error
+	done     chan struct{}
+	interval time.Duration
+	once     sync.Once
+}
+
+func newPollWatcher(interval time.Duration) *pollWatcher {
+	w := &pollWatcher{
+		dirs:     make(map[string]map[string]fileState),
+		events:   make(chan fsnotify.Event),
+		errors:   make(chan error),
+		done:     make(chan struct{}),
+		interval: interval,
+	}
+	go w.run()
+	return w
+}
+
+func (w *pollWatcher) Add(name string) error {
+	entries, err := scanDir(name)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	select {
+	case <-w.done:
+		return errors.New("poll watcher closed")
+	default:
+	}
+	w.dirs[name] = entries
+	return nil
+}
+
+func (w *pollWatcher) Remove(name string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.dirs, name)
+	return nil
+}
+
+func (w *pollWatcher) WatchList() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	list := make([]string, 0, len(w.dirs))
+	for name := range w.dirs {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func (w *pollWatcher) Close() error {
+	w.once.Do(func() {
+		close(w.done)
+	})
+	return nil
+}
+
+func (w *pollWatcher) Events() <-chan fsnotify.Event {
+	return w.events
+}
+
+func (w *pollWatcher) Errors() <-chan error {
+	return w.errors
+}
+
+func (w *pollWatcher) run() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	defer close(w.events)
+	defer close(w.errors)
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			for _, event := range w.scan() {
+				select {
+				case w.events <- event:
+				case <-w.done:
+					return
+				}
+			}
+		}
+	}
+}
+
+// scan rescans every watched directory and returns the changes since the
+// previous scan.
+func (w *pollWatcher) scan() []fsnotify.Event {
+	var events []fsnotify.Event
+	for _, dir := range w.WatchList() {
+		current, err := scanDir(dir)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				w.Remove(dir)
+				continue
+			}
+			select {
+			case w.errors <- err:
+			case <-w.done:
+			}
+			continue
+		}
+		w.mu.Lock()
+		previous, ok := w.dirs[dir]
+		if ok {
+			w.dirs[dir] = current
+		}
+		w.mu.Unlock()
+		if !ok {
+			continue
+		}
+		for name, st := range current {
+			prev, ok := previous[name]
+			if !ok {
+				events = append(events, fsnotify.Event{Name: name, Op: fsnotify.Create})
+			} else if !st.isDir && (!st.modTime.Equal(prev.modTime) || st.size != prev.size) {
+				events = append(events, fsnotify.Event{Name: name, Op: fsnotify.Write})
+			}
+		}
+		for name := range previous {
+			if _, ok := current[name]; !ok {
+				events = append(events, fsnotify.Event{Name: name, Op: fsnotify.Remove})
+			}
+		}
+	}
+	return events
+}
+
+func scanDir(dir string) (map[string]fileState, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[string]fileState, len(entries))
+	for _, entry := range entries {
+		fi, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		states[path.Join(dir, entry.Name())] = fileState{
+			modTime: fi.ModTime(),
+			size:    fi.Size(),
+			isDir:   fi.IsDir(),
+		}
+	}
+	return states, nil
+}
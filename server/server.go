@@ -1,74 +1,271 @@
 package server
 
 import (
-	"bytes"
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"net"
 	"net/http"
 	"os"
 	"path"
+	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/goui-org/gouix/build"
+	"github.com/goui-org/gouix/certs"
 	"github.com/goui-org/gouix/config"
+	"github.com/goui-org/gouix/headers"
+	"github.com/goui-org/gouix/mocks"
+	"github.com/goui-org/gouix/symbols"
 	"github.com/goui-org/gouix/utils"
 
-	"github.com/fsnotify/fsnotify"
 	"github.com/gorilla/websocket"
 	"github.com/twharmon/slices"
 )
 
+// Options are set from command line flags and are kept when goui.yml is
+// reloaded.
+type Options struct {
+	// Mock answers requests from the mocks before proxying them.
+	Mock bool
+	// AccessLog prints every request served.
+	AccessLog bool
+	// Throttle simulates a slow network when set.
+	Throttle *Throttle
+}
+
 type Server struct {
-	upgrader  websocket.Upgrader
-	listeners []*websocket.Conn
-	mu        sync.Mutex
-	watcher   *fsnotify.Watcher
-	loaded    bool
-	build     *build.Build
-	config    *config.Config
+	upgrader websocket.Upgrader
+	clients  map[*client]struct{}
+	mu       sync.Mutex
+	// pending is the last build error, delivered to tabs that connect
+	// before the next successful build.
+	pending *Message
+	// symbols symbolizes wasm stack traces when build.debug is set.
+	symbols *symbols.Table
+	version int
+	watcher watcher
+	ignore  *ignorer
+	// modules are directories of local modules the project depends on.
+	modules []string
+	build   *build.Build
+	config  *config.Config
+	// port is the port the server is bound to, which may differ from the
+	// configured one.
+	port int
+	// ctx is cancelled when the server shuts down, stopping any build.
+	ctx context.Context
+	// buildMu serializes builds, so shutdown can wait for the one in
+	// flight before removing the build directory.
+	buildMu sync.Mutex
+	pumps   sync.WaitGroup
+	opts    *Options
+	mocks   *mocks.Set
 }
 
-func New(cfg *config.Config) (*Server, error) {
+const (
+	maxPortAttempts = 20
+	shutdownTimeout = time.Second * 5
+)
+
+func New(cfg *config.Config, opts *Options) (*Server, error) {
 	os.Setenv("DEBUG", "true")
 	s := &Server{
-		config: cfg,
-		build:  build.New(cfg),
+		opts:    opts,
+		config:  cfg,
+		build:   build.New(cfg),
+		clients: make(map[*client]struct{}),
+		ctx:     context.Background(),
 	}
 	if err := s.watchAll(); err != nil {
 		return nil, fmt.Errorf("devserver.New: %w", err)
 	}
-	go s.watch()
 	return s, nil
 }
 
-func (s *Server) Run() error {
+// Run serves the app until ctx is done, then shuts down gracefully.
+func (s *Server) Run(ctx context.Context) error {
 	http.HandleFunc("/hot", s.ws())
 	http.HandleFunc("/", s.files())
-	if err := s.build.Run(); err != nil {
-		go s.reportBuildError(fmt.Errorf("devserver.Server.Run: %s", err))
+	ln, err := s.listen()
+	if err != nil {
+		return fmt.Errorf("devserver.Server.Run: %w", err)
+	}
+	// certificate problems are reported before a browser is opened at an
+	// address that won't answer
+	var tlsConfig *tls.Config
+	if s.config.Server.HTTPS {
+		if tlsConfig, err = certs.TLSConfig(s.config.Server); err != nil {
+			ln.Close()
+			return fmt.Errorf("devserver.Server.Run: %w", err)
+		}
+	}
+	s.ctx = ctx
+	s.loadMocks()
+	go s.watch()
+	if err := s.runBuild(); err != nil {
+		s.reportBuildError(fmt.Errorf("devserver.Server.Run: %s", err))
+	} else {
+		s.loadSymbols()
 	}
 	s.openBrowser()
-	return http.ListenAndServe(fmt.Sprintf(":%d", s.config.Server.Port), nil)
+	srv := &http.Server{
+		Handler: s.logRequests(s.throttle(headers.Handler(s.serverConfig, http.DefaultServeMux))),
+		// Requests are cancelled on shutdown, so throttled and delayed
+		// responses don't hold it up.
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	errc := make(chan error, 1)
+	if tlsConfig != nil {
+		srv.TLSConfig = tlsConfig
+		go func() { errc <- srv.ServeTLS(ln, "", "") }()
+	} else {
+		go func() { errc <- srv.Serve(ln) }()
+	}
+	select {
+	case err := <-errc:
+		s.Shutdown()
+		return fmt.Errorf("devserver.Server.Run: %w", err)
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	s.disconnectClients()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		fmt.Printf("devserver.Server.Run: %s\n", err)
+	}
+	return s.Shutdown()
 }
 
+// runBuild builds the app unless the server is shutting down.
+func (s *Server) runBuild() error {
+	s.buildMu.Lock()
+	defer s.buildMu.Unlock()
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	return s.build.RunContext(s.ctx)
+}
+
+// listen binds the configured port. Unless server.strict_port is set, the
+// following ports are tried when it is taken, and the config is updated
+// with the port actually used.
+func (s *Server) listen() (net.Listener, error) {
+	cfg := s.config.Server
+	port := cfg.Port
+	for {
+		ln, err := net.Listen("tcp", net.JoinHostPort(cfg.Host, strconv.Itoa(port)))
+		if err == nil {
+			s.port = port
+			cfg.Port = port
+			return ln, nil
+		}
+		if cfg.StrictPort || !isAddrInUse(err) || port-cfg.Port >= maxPortAttempts {
+			return nil, fmt.Errorf("devserver.Server.listen: %w", err)
+		}
+		port++
+	}
+}
+
+func isAddrInUse(err error) bool {
+	if errors.Is(err, syscall.EADDRINUSE) {
+		return true
+	}
+	// Windows reports WSAEADDRINUSE, which syscall doesn't define.
+	var errno syscall.Errno
+	return errors.As(err, &errno) && errno == 10048
+}
+
+// reloadConfig rereads goui.yml, keeping the port the server is bound to.
+func (s *Server) reloadConfig() {
+	cfg := config.Get()
+	cfg.Server.Port = s.port
+	s.mu.Lock()
+	s.config = cfg
+	s.mu.Unlock()
+	s.build.ReplaceConfig(cfg)
+	s.reloadIgnore(cfg.Server.Watch.Ignore)
+}
+
+// loadMocks reads the mock routes when serving with --mock.
+func (s *Server) loadMocks() {
+	if !s.opts.Mock {
+		return
+	}
+	set, err := mocks.Load(s.serverConfig().Mocks)
+	if err != nil {
+		fmt.Printf("devserver.Server.loadMocks: %s\n", err)
+		return
+	}
+	s.mu.Lock()
+	s.mocks = set
+	s.mu.Unlock()
+}
+
+func (s *Server) serverConfig() *config.ServerConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.config.Server
+}
+
+func (s *Server) buildConfig() *config.BuildConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.config.Build
+}
+
+// Shutdown waits for the build in flight, which stops once the context
+// passed to Run is done, then removes the build directory and stops
+// watching files.
 func (s *Server) Shutdown() error {
+	s.buildMu.Lock()
+	defer s.buildMu.Unlock()
+	if err := s.watcher.Close(); err != nil {
+		return fmt.Errorf("devserver.Server.Shutdown: %w", err)
+	}
 	if err := os.RemoveAll(s.build.BuildDir()); err != nil {
-		return fmt.Errorf("build.Run: %w", err)
+		return fmt.Errorf("devserver.Server.Shutdown: %w", err)
 	}
-	return s.watcher.Close()
+	return nil
+}
+
+// disconnectClients tells every tab the server stopped, so it can wait for
+// it to come back, and closes their connections.
+func (s *Server) disconnectClients() {
+	b, err := newMessage(MessageStopped).encode()
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	for c := range s.clients {
+		c.stop(b)
+	}
+	s.mu.Unlock()
+	s.pumps.Wait()
 }
 
 func (s *Server) files() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		filePath := path.Join(s.build.BuildDir(), r.URL.Path)
 		if _, err := os.Stat(filePath); errors.Is(err, os.ErrNotExist) {
+			// mocks take precedence over the proxy, never over the build
+			s.mu.Lock()
+			set := s.mocks
+			s.mu.Unlock()
+			if set.Serve(w, r) {
+				setSource(r, sourceMock)
+				return
+			}
 			filePath = strings.TrimPrefix(filePath, s.build.BuildDir())
-			resp, err := http.Get(s.config.Server.Proxy + filePath)
+			setSource(r, sourceProxy)
+			resp, err := http.Get(s.serverConfig().Proxy + filePath)
 			if err != nil {
 				w.WriteHeader(http.StatusNotFound)
 				return
@@ -86,6 +283,7 @@ func (s *Server) files() http.HandlerFunc {
 			w.Write(b)
 			return
 		}
+		setSource(r, sourceBuild)
 		http.ServeFile(w, r, path.Join(s.build.BuildDir(), r.URL.Path))
 	}
 }
@@ -97,120 +295,139 @@ func (s *Server) ws() http.HandlerFunc {
 			fmt.Printf("devserver.Server.ws: %s\n", err)
 			return
 		}
-		defer conn.Close()
+		c := newClient(conn)
 		s.mu.Lock()
-		s.listeners = append(s.listeners, conn)
+		s.clients[c] = struct{}{}
+		if s.pending != nil {
+			if b, err := s.pending.encode(); err == nil {
+				c.enqueue(b)
+			}
+		}
 		s.mu.Unlock()
+		defer s.removeClient(c)
+		s.pumps.Add(1)
+		go func() {
+			defer s.pumps.Done()
+			c.writePump(func() []byte {
+				b, _ := newMessage(MessagePing).encode()
+				return b
+			})
+		}()
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		conn.SetPongHandler(func(string) error {
+			c.seen()
+			return conn.SetReadDeadline(time.Now().Add(pongWait))
+		})
 		for {
 			_, p, err := conn.ReadMessage()
 			if err != nil {
 				break
 			}
-			if bytes.Equal(p, []byte("loaded")) {
-				s.loaded = true
+			c.seen()
+			conn.SetReadDeadline(time.Now().Add(pongWait))
+			msg, err := decodeMessage(p)
+			if err != nil {
+				fmt.Printf("devserver.Server.ws: %s\n", err)
+				continue
+			}
+			switch msg.Type {
+			case MessageLoaded:
+				if !c.markLoaded(msg.URL) {
+					return
+				}
+			case MessageConsole:
+				s.console(c, msg)
 			}
 		}
-		s.mu.Lock()
-		s.listeners = slices.Filter(s.listeners, func(listener *websocket.Conn) bool {
-			return listener != conn
-		})
-		s.mu.Unlock()
 	}
 }
 
-func (s *Server) watch() {
-	var q []struct{}
-	flush := func() {
-		if len(q) > 0 {
-			q = q[:0]
-			s.config = config.Get()
-			s.build.ReplaceConfig(s.config)
-			if err := s.build.Run(); err != nil {
-				s.reportBuildError(fmt.Errorf("devserver.Server.watch: %s", err))
-			} else {
-				s.sendMessage("reload")
-			}
+func (s *Server) removeClient(c *client) {
+	c.close()
+	s.mu.Lock()
+	delete(s.clients, c)
+	s.mu.Unlock()
+}
+
+// reloadMessage returns the message that brings tabs up to date after a
+// build. Only changes to Go sources can be hot swapped.
+func (s *Server) reloadMessage(changed []string) *Message {
+	if !s.serverConfig().HMR {
+		return newMessage(MessageReload)
+	}
+	for _, name := range changed {
+		if filepath.Ext(name) != ".go" {
+			return newMessage(MessageReload)
 		}
 	}
-	for {
-		select {
-		case event, ok := <-s.watcher.Events:
-			if !ok {
-				return
-			}
-			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove) > 0 {
-				q = append(q, struct{}{})
-				time.AfterFunc(time.Millisecond*10, flush)
-			}
-		case err, ok := <-s.watcher.Errors:
-			if !ok {
-				return
-			}
-			log.Printf("devserver.Server.watch: %s\n", err)
+	s.mu.Lock()
+	s.version++
+	msg := newMessage(MessageSwap)
+	msg.URL = fmt.Sprintf("main.wasm?v=%d", s.version)
+	s.mu.Unlock()
+	return msg
+}
+
+func (s *Server) loadSymbols() {
+	var table *symbols.Table
+	if s.buildConfig().Debug {
+		var err error
+		table, err = symbols.Load(path.Join(s.build.BuildDir(), "main.wasm"))
+		if err != nil {
+			fmt.Printf("devserver.Server.loadSymbols: %s\n", err)
 		}
 	}
+	s.mu.Lock()
+	s.symbols = table
+	s.mu.Unlock()
 }
 
 func (s *Server) reportBuildError(err error) {
 	fmt.Printf("\nError: %s\n\n", err)
-	s.sendMessage(err.Error())
-}
-
-func (s *Server) watchAll() error {
-	var err error
-	s.watcher, err = fsnotify.NewWatcher()
-	if err != nil {
-		return fmt.Errorf("devserver.Server.getWatcher: %w", err)
-	}
-	if err := s.watchDir("."); err != nil {
-		return fmt.Errorf("devserver.Server.watchAll: %w", err)
-	}
-	return nil
+	msg := newMessage(MessageError)
+	msg.Error = err.Error()
+	s.mu.Lock()
+	s.pending = msg
+	s.mu.Unlock()
+	s.sendMessage(msg)
 }
 
-func (s *Server) watchDir(dir string) error {
-	if dir == path.Join(".", "build") {
-		return nil
-	}
-	if err := s.watcher.Add(dir); err != nil {
-		return fmt.Errorf("devserver.Server.watchDir: %w", err)
-	}
-	if fi, err := os.Stat(dir); err != nil {
-		return fmt.Errorf("devserver.Server.watchDir: %w", err)
-	} else if fi.IsDir() {
-		fis, err := os.ReadDir(dir)
-		if err != nil {
-			return fmt.Errorf("devserver.Server.watchDir: %w", err)
+// stylesheets reports whether every changed file is a stylesheet in the
+// public directory, returning their paths relative to it.
+func stylesheets(changed []string) ([]string, bool) {
+	var sheets []string
+	for _, name := range changed {
+		rel, err := filepath.Rel("public", name)
+		if err != nil || strings.HasPrefix(rel, "..") || filepath.Ext(rel) != ".css" {
+			return nil, false
 		}
-		for _, fi := range fis {
-			if fi.IsDir() {
-				if err := s.watchDir(path.Join(dir, fi.Name())); err != nil {
-					return err
-				}
-			}
+		if !slices.Contains(sheets, filepath.ToSlash(rel)) {
+			sheets = append(sheets, filepath.ToSlash(rel))
 		}
 	}
-	return nil
+	return sheets, len(sheets) > 0
 }
 
-func (s *Server) sendMessage(msg string) {
-	for {
-		if s.loaded {
-			break
-		}
-		time.Sleep(time.Millisecond * 10)
+// sendMessage broadcasts msg to every connected tab without blocking.
+// Tabs whose send buffer is full are disconnected.
+func (s *Server) sendMessage(msg *Message) {
+	b, err := msg.encode()
+	if err != nil {
+		fmt.Printf("devserver.Server.sendMessage: %s\n", err)
+		return
 	}
 	s.mu.Lock()
-	for i := len(s.listeners) - 1; i >= 0; i-- {
-		if s.listeners[i].WriteMessage(websocket.TextMessage, []byte(msg)) != nil {
-			s.listeners = slices.Splice(s.listeners, i, 1)
+	defer s.mu.Unlock()
+	for c := range s.clients {
+		if !c.enqueue(b) {
+			c.close()
+			delete(s.clients, c)
 		}
 	}
-	s.mu.Unlock()
 }
 
 func (s *Server) openBrowser() {
-	url := fmt.Sprintf("http://localhost:%d", s.config.Server.Port)
+	url := s.serverConfig().URL()
 	var err error
 	switch runtime.GOOS {
 	case "linux":
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Throttle limits the bandwidth and adds latency to responses to simulate
+// slow networks.
+type Throttle struct {
+	// Kbps is the bandwidth in kilobits per second.
+	Kbps    int
+	Latency time.Duration
+}
+
+var throttlePresets = map[string]*Throttle{
+	"slow-3g": {Kbps: 400, Latency: time.Millisecond * 2000},
+	"3g":      {Kbps: 750, Latency: time.Millisecond * 300},
+	"slow-4g": {Kbps: 1600, Latency: time.Millisecond * 562},
+	"4g":      {Kbps: 9000, Latency: time.Millisecond * 170},
+}
+
+// ParseThrottle parses a preset name (slow-3g, 3g, slow-4g or 4g) or a
+// custom "<kbps>,<latency>" setting, where latency is in milliseconds or a
+// duration such as 150ms.
+func ParseThrottle(s string) (*Throttle, error) {
+	fail := func() (*Throttle, error) {
+		return nil, fmt.Errorf("server.ParseThrottle: invalid throttle %q", s)
+	}
+	if t, ok := throttlePresets[strings.ToLower(s)]; ok {
+		return t, nil
+	}
+	kbps, latency, ok := strings.Cut(s, ",")
+	if !ok {
+		return fail()
+	}
+	t := &Throttle{}
+	var err error
+	if t.Kbps, err = strconv.Atoi(strings.TrimSpace(kbps)); err != nil || t.Kbps <= 0 {
+		return fail()
+	}
+	latency = strings.TrimSpace(latency)
+	if ms, err := strconv.Atoi(latency); err == nil {
+		t.Latency = time.Duration(ms) * time.Millisecond
+	} else if t.Latency, err = time.ParseDuration(latency); err != nil {
+		return fail()
+	}
+	return t, nil
+}
+
+func (t *Throttle) String() string {
+	return fmt.Sprintf("%d kbps, %s latency", t.Kbps, t.Latency)
+}
+
+// throttleChunk is how often throttled writes are released.
+const throttleChunk = time.Millisecond * 50
+
+// throttle delays responses by the configured latency and paces their
+// bodies to the configured bandwidth. The hot reload socket isn't
+// throttled.
+func (s *Server) throttle(next http.Handler) http.Handler {
+	t := s.opts.Throttle
+	if t == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/hot" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		select {
+		case <-time.After(t.Latency):
+		case <-r.Context().Done():
+			return
+		}
+		next.ServeHTTP(&throttledWriter{ResponseWriter: w, r: r, bytesPerChunk: t.Kbps * 1000 / 8 * int(throttleChunk) / int(time.Second)}, r)
+	})
+}
+
+type throttledWriter struct {
+	http.ResponseWriter
+	r             *http.Request
+	bytesPerChunk int
+}
+
+func (w *throttledWriter) Write(b []byte) (int, error) {
+	written := 0
+	for len(b) > 0 {
+		n := min(len(b), max(w.bytesPerChunk, 1))
+		m, err := w.ResponseWriter.Write(b[:n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+		http.NewResponseController(w.ResponseWriter).Flush()
+		b = b[n:]
+		select {
+		case <-time.After(throttleChunk):
+		case <-w.r.Context().Done():
+			return written, w.r.Context().Err()
+		}
+	}
+	return written, nil
+}
+
+func (w *throttledWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
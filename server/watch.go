@@ -0,0 +1,295 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/goui-org/gouix/mocks"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/twharmon/slices"
+)
+
+// watcher is implemented by fsnotify and by the polling fallback.
+type watcher interface {
+	Add(name string) error
+	Remove(name string) error
+	WatchList() []string
+	Close() error
+	Events() <-chan fsnotify.Event
+	Errors() <-chan error
+}
+
+type nativeWatcher struct {
+	*fsnotify.Watcher
+}
+
+func (w nativeWatcher) Events() <-chan fsnotify.Event {
+	return w.Watcher.Events
+}
+
+func (w nativeWatcher) Errors() <-chan error {
+	return w.Watcher.Errors
+}
+
+func (s *Server) watch() {
+	var q []string
+	var qmu sync.Mutex
+	flush := func() {
+		qmu.Lock()
+		changed := q
+		q = nil
+		qmu.Unlock()
+		if len(changed) > 0 {
+			if onlyMocks(changed) {
+				s.loadMocks()
+				return
+			}
+			if sheets, ok := stylesheets(changed); ok {
+				if err := s.build.CopyPublic(); err != nil {
+					s.reportBuildError(fmt.Errorf("devserver.Server.watch: %s", err))
+					return
+				}
+				msg := newMessage(MessageCSS)
+				msg.Paths = sheets
+				s.sendMessage(msg)
+				return
+			}
+			s.reloadConfig()
+			s.loadMocks()
+			s.sendMessage(newMessage(MessageBuilding))
+			start := time.Now()
+			if err := s.runBuild(); err != nil {
+				if s.ctx.Err() != nil {
+					return
+				}
+				s.reportBuildError(fmt.Errorf("devserver.Server.watch: %s", err))
+			} else {
+				s.mu.Lock()
+				s.pending = nil
+				s.mu.Unlock()
+				s.loadSymbols()
+				if modulesChanged(changed) {
+					s.watchModules()
+				}
+				msg := newMessage(MessageBuilt)
+				msg.Duration = time.Since(start).Round(time.Millisecond).String()
+				s.sendMessage(msg)
+				s.sendMessage(s.reloadMessage(changed))
+			}
+		}
+	}
+	for {
+		select {
+		case event, ok := <-s.watcher.Events():
+			if !ok {
+				return
+			}
+			fi, err := os.Stat(event.Name)
+			isDir := err == nil && fi.IsDir()
+			if s.ignore.match(event.Name, isDir) {
+				continue
+			}
+			if filepath.IsAbs(event.Name) && !isDir && !isModuleSource(event.Name) {
+				continue
+			}
+			if event.Op&fsnotify.Create > 0 && isDir {
+				if err := s.watchDir(event.Name); err != nil {
+					log.Printf("devserver.Server.watch: %s\n", err)
+				}
+			}
+			if event.Op&(fsnotify.Remove|fsnotify.Rename) > 0 {
+				s.unwatchDir(event.Name)
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) > 0 {
+				qmu.Lock()
+				q = append(q, event.Name)
+				qmu.Unlock()
+				time.AfterFunc(time.Millisecond*10, flush)
+			}
+		case err, ok := <-s.watcher.Errors():
+			if !ok {
+				return
+			}
+			log.Printf("devserver.Server.watch: %s\n", err)
+		}
+	}
+}
+
+func (s *Server) watchAll() error {
+	cfg := s.config.Server.Watch
+	s.ignore = newIgnorer(cfg.Ignore)
+	mode := cfg.Mode
+	if mode == "auto" && isWSLMount() {
+		mode = "poll"
+	}
+	if mode != "poll" {
+		w, err := fsnotify.NewWatcher()
+		if err == nil {
+			s.watcher = nativeWatcher{w}
+			err = s.watchDir(".")
+			if err == nil {
+				s.watchModules()
+				return nil
+			}
+			w.Close()
+		}
+		if mode == "native" || !isResourceLimit(err) {
+			return fmt.Errorf("devserver.Server.watchAll: %w", err)
+		}
+		log.Printf("devserver.Server.watchAll: %s, falling back to polling\n", err)
+	}
+	s.watcher = newPollWatcher(cfg.Interval)
+	if err := s.watchDir("."); err != nil {
+		return fmt.Errorf("devserver.Server.watchAll: %w", err)
+	}
+	s.watchModules()
+	return nil
+}
+
+// watchModules watches the sources of modules the project uses from the
+// local disk. Failing to resolve them only disables their watching.
+func (s *Server) watchModules() {
+	dirs, err := localModules()
+	if err != nil {
+		log.Printf("devserver.Server.watchModules: %s\n", err)
+		return
+	}
+	for _, dir := range dirs {
+		s.mu.Lock()
+		watched := slices.Contains(s.modules, dir)
+		if !watched {
+			s.modules = append(s.modules, dir)
+		}
+		s.mu.Unlock()
+		if watched {
+			continue
+		}
+		s.ignore.addRoot(dir)
+		if err := s.watchDir(dir); err != nil {
+			log.Printf("devserver.Server.watchModules: %s\n", err)
+		}
+	}
+}
+
+// onlyMocks reports whether every changed file is in the mocks directory,
+// which doesn't need a rebuild.
+func onlyMocks(changed []string) bool {
+	for _, name := range changed {
+		rel, err := filepath.Rel(mocks.Dir, name)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return false
+		}
+	}
+	return true
+}
+
+// modulesChanged reports whether go.mod or go.work changed, which may
+// add local modules to watch.
+func modulesChanged(changed []string) bool {
+	for _, name := range changed {
+		switch filepath.Clean(name) {
+		case "go.mod", "go.work":
+			return true
+		}
+	}
+	return false
+}
+
+// isResourceLimit reports whether err means the system ran out of inotify
+// watches or file descriptors.
+func isResourceLimit(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE) || errors.Is(err, syscall.ENFILE)
+}
+
+// isWSLMount reports whether the working directory is a Windows drive
+// mounted into WSL, where filesystem notifications aren't delivered.
+func isWSLMount() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	wd, err := os.Getwd()
+	if err != nil || !strings.HasPrefix(wd, "/mnt/") {
+		return false
+	}
+	version, err := os.ReadFile("/proc/version")
+	return err == nil && strings.Contains(strings.ToLower(string(version)), "microsoft")
+}
+
+func (s *Server) watchDir(dir string) error {
+	if s.ignore.match(dir, true) {
+		return nil
+	}
+	if err := s.watcher.Add(dir); err != nil {
+		return fmt.Errorf("devserver.Server.watchDir: %w", err)
+	}
+	if fi, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("devserver.Server.watchDir: %w", err)
+	} else if fi.IsDir() {
+		fis, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("devserver.Server.watchDir: %w", err)
+		}
+		for _, fi := range fis {
+			if fi.IsDir() {
+				if err := s.watchDir(path.Join(dir, fi.Name())); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// reloadIgnore rereads the ignore patterns of goui.yml and .gitignore. If
+// they changed, directories they now ignore stop being watched and those
+// they no longer ignore start being watched.
+func (s *Server) reloadIgnore(patterns []string) {
+	if !s.ignore.load(patterns) {
+		return
+	}
+	watched := make(map[string]bool)
+	for _, name := range s.watcher.WatchList() {
+		if s.ignore.match(name, true) {
+			s.watcher.Remove(name)
+		} else {
+			watched[name] = true
+		}
+	}
+	err := filepath.WalkDir(".", func(name string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		if s.ignore.match(name, true) {
+			return filepath.SkipDir
+		}
+		name = filepath.ToSlash(name)
+		if !watched[name] {
+			return s.watcher.Add(name)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("devserver.Server.reloadIgnore: %s\n", err)
+	}
+}
+
+// unwatchDir stops watching dir and every directory below it. Paths that
+// aren't watched are ignored.
+func (s *Server) unwatchDir(dir string) {
+	prefix := path.Clean(dir) + "/"
+	for _, name := range s.watcher.WatchList() {
+		if name == dir || strings.HasPrefix(name, prefix) {
+			s.watcher.Remove(name)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package show
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/goui-org/gouix/build"
+	"github.com/goui-org/gouix/config"
+	"github.com/goui-org/gouix/server"
+	"github.com/goui-org/gouix/utils"
+)
+
+// Options configure what Settings reports besides goui.yml.
+type Options struct {
+	// Serve are the options of gouix serve.
+	Serve *server.Options
+	// Flags reports whether the serve flag with the given name was set.
+	Flags func(name string) bool
+	// LookupEnv looks up environment variables. It defaults to
+	// os.LookupEnv.
+	LookupEnv func(key string) (string, bool)
+}
+
+func (opts *Options) setDefaults() {
+	if opts.Serve == nil {
+		opts.Serve = &server.Options{}
+	}
+	if opts.Flags == nil {
+		opts.Flags = func(string) bool { return false }
+	}
+	if opts.LookupEnv == nil {
+		opts.LookupEnv = os.LookupEnv
+	}
+}
+
+// Settings returns the config in the goui.yml in yml and the settings in
+// effect for serve with opts, including those set by its flags and the
+// environment.
+func Settings(yml []byte, opts *Options) (*config.Config, []*config.Setting, error) {
+	fail := func(err error) (*config.Config, []*config.Setting, error) {
+		return nil, nil, fmt.Errorf("show.Settings: %w", err)
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	opts.setDefaults()
+	cfg, settings, err := config.Resolve(yml)
+	if err != nil {
+		return fail(err)
+	}
+	for _, s := range settings {
+		switch {
+		case s.Key == "server.access_log" && opts.Serve.AccessLog:
+			s.Value, s.Source = "true", config.SourceFlag+" --log"
+		case s.Key == "build.panic" && build.PanicMode(cfg.Build) != cfg.Build.Panic:
+			s.Value, s.Source = build.PanicMode(cfg.Build), fmt.Sprintf("no_traps with wasm_opt, instead of %s from %s", cfg.Build.Panic, s.Source)
+		}
+	}
+	flag := func(key, name string, value any) {
+		s := &config.Setting{Key: key, Value: fmt.Sprint(value), Source: config.SourceDefault}
+		if opts.Flags(name) {
+			s.Source = config.SourceFlag + " --" + name
+		}
+		settings = append(settings, s)
+	}
+	throttle := "off"
+	if opts.Serve.Throttle != nil {
+		throttle = opts.Serve.Throttle.String()
+	}
+	flag("serve.mock", "mock", opts.Serve.Mock)
+	flag("serve.throttle", "throttle", throttle)
+	env := &config.Setting{Key: "env.DEBUG", Value: `""`, Source: config.SourceDefault}
+	if debug, ok := opts.LookupEnv("DEBUG"); ok {
+		env.Value, env.Source = debug, config.SourceEnv
+	}
+	settings = append(settings, env)
+	return cfg, settings, nil
+}
+
+// Config writes the settings in effect for serve with opts to w, and the
+// commands gouix build and gouix serve compile with.
+func Config(w io.Writer, yml []byte, opts *Options) error {
+	cfg, settings, err := Settings(yml, opts)
+	if err != nil {
+		return fmt.Errorf("show.Config: %w", err)
+	}
+	width := 0
+	for _, s := range settings {
+		width = max(width, len(s.Key))
+	}
+	faint := color.New(color.Faint)
+	debug := false
+	for _, s := range settings {
+		fmt.Fprintf(w, "%s  %s", utils.PadRight(s.Key, width), s.Value)
+		faint.Fprintf(w, "  (%s)\n", s.Source)
+		if s.Key == "env.DEBUG" {
+			debug = s.Value == "true"
+		}
+	}
+
+	b := build.New(cfg)
+	devDir := build.DevBuildDir("<id>")
+	if debug {
+		fmt.Fprintln(w, "\ngouix build, which makes a development build because DEBUG=true:")
+		printCommands(w, b.Commands(devDir))
+	} else {
+		fmt.Fprintln(w, "\ngouix build:")
+		printCommands(w, b.Commands("build"))
+	}
+	fmt.Fprintln(w, "\ngouix serve, which sets DEBUG=true and builds to a new <id> every run:")
+	printCommands(w, b.Commands(devDir))
+	if !cfg.Build.WASMOpt {
+		faint.Fprintln(w, "\nwasm-opt doesn't run, wasm_opt is off")
+	}
+	return nil
+}
+
+func printCommands(w io.Writer, cmds [][]string) {
+	for _, cmd := range cmds {
+		fmt.Fprintf(w, "\t%s\n", strings.Join(quote(cmd), " "))
+	}
+}
+
+// quote quotes the arguments a shell would split or expand.
+func quote(args []string) []string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\$*?[]{}()<>|&;#~`") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		quoted[i] = arg
+	}
+	return quoted
+}
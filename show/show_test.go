@@ -0,0 +1,71 @@
+package show
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/goui-org/gouix/server"
+)
+
+func TestSettings(t *testing.T) {
+	yml := []byte("build:\n  opt: z\n  wasm_opt: true\n  no_traps: true\n")
+	throttle, err := server.ParseThrottle("3g")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := &Options{
+		Serve: &server.Options{AccessLog: true, Throttle: throttle},
+		Flags: func(name string) bool { return name == "log" || name == "throttle" },
+		LookupEnv: func(key string) (string, bool) {
+			if key == "DEBUG" {
+				return "true", true
+			}
+			return "", false
+		},
+	}
+	_, settings, err := Settings(yml, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, s := range settings {
+		got[s.Key] = s.Value + " (" + s.Source + ")"
+	}
+	want := map[string]string{
+		"build.opt":         "z (goui.yml)",
+		"build.entry":       "src (default)",
+		"build.panic":       "trap (no_traps with wasm_opt, instead of print from default)",
+		"server.access_log": "true (flag --log)",
+		"serve.mock":        "false (default)",
+		"serve.throttle":    throttle.String() + " (flag --throttle)",
+		"env.DEBUG":         "true (env)",
+	}
+	for key, w := range want {
+		if got[key] != w {
+			t.Errorf("%s = %q, want %q", key, got[key], w)
+		}
+	}
+}
+
+func TestConfig(t *testing.T) {
+	noEnv := func(string) (string, bool) { return "", false }
+	var buf bytes.Buffer
+	if err := Config(&buf, []byte("build:\n  opt: z\n"), &Options{LookupEnv: noEnv}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"env.DEBUG", "\ngouix build:\n", "-opt=z", "wasm_opt is off"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Config() doesn't print %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	got := strings.Join(quote([]string{"tinygo", "-o", "build dir/main.wasm", "", "it's"}), " ")
+	want := `tinygo -o 'build dir/main.wasm' '' 'it'\''s'`
+	if got != want {
+		t.Errorf("quote() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,275 @@
+package symbols
+
+import (
+	"bytes"
+	"debug/dwarf"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	sectionCustom = 0
+	sectionCode   = 10
+	nameFunctions = 1
+)
+
+var wasmMagic = []byte{0x00, 'a', 's', 'm'}
+
+// frame matches a wasm frame in a browser stack trace, eg.
+// "wasm-function[1234]:0x5a3b" in both Chrome and Firefox.
+var frame = regexp.MustCompile(`wasm-function\[(\d+)\]:0x([0-9a-fA-F]+)`)
+
+type lineEntry struct {
+	addr uint64
+	file string
+	line int
+}
+
+// Table maps wasm function indices and code offsets in a compiled module to
+// Go function names and source locations.
+type Table struct {
+	names      map[int]string
+	lines      []lineEntry
+	codeOffset uint64
+}
+
+// Load reads the name section and DWARF sections from the wasm module in
+// file. Modules built without debug information yield a table that leaves
+// traces untouched.
+func Load(file string) (*Table, error) {
+	fail := func(err error) (*Table, error) {
+		return nil, fmt.Errorf("symbols.Load: %w", err)
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return fail(err)
+	}
+	if len(b) < 8 || !bytes.Equal(b[:4], wasmMagic) {
+		return fail(errors.New("not a wasm module"))
+	}
+	t := &Table{names: make(map[int]string)}
+	debug := make(map[string][]byte)
+	r := &reader{b: b, off: 8}
+	for !r.done() {
+		id := r.byte()
+		size := r.size()
+		if r.err != nil {
+			return fail(errors.New("malformed section"))
+		}
+		start := r.off
+		end := start + size
+		switch id {
+		case sectionCode:
+			t.codeOffset = uint64(start)
+		case sectionCustom:
+			sr := &reader{b: b[:end], off: start}
+			name := sr.name()
+			if sr.err != nil {
+				return fail(errors.New("malformed custom section"))
+			}
+			if name == "name" {
+				t.readNames(&reader{b: b[:end], off: sr.off})
+			} else if strings.HasPrefix(name, ".debug_") {
+				debug[strings.TrimPrefix(name, ".debug_")] = b[sr.off:end]
+			}
+		}
+		r.off = end
+	}
+	if debug["info"] != nil {
+		if err := t.readLines(debug); err != nil {
+			return fail(err)
+		}
+	}
+	return t, nil
+}
+
+func (t *Table) readNames(r *reader) {
+	for !r.done() && r.err == nil {
+		id := r.byte()
+		size := r.size()
+		if r.err != nil {
+			return
+		}
+		end := r.off + size
+		if id == nameFunctions {
+			sr := &reader{b: r.b[:end], off: r.off}
+			count := int(sr.uleb())
+			for i := 0; i < count && sr.err == nil; i++ {
+				idx := int(sr.uleb())
+				t.names[idx] = sr.name()
+			}
+		}
+		r.off = end
+	}
+}
+
+func (t *Table) readLines(sections map[string][]byte) error {
+	d, err := dwarf.New(sections["abbrev"], sections["aranges"], sections["frame"], sections["info"], sections["line"], sections["pubnames"], sections["ranges"], sections["str"])
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{"addr", "line_str", "loclists", "rnglists", "str_offsets"} {
+		if b, ok := sections[name]; ok {
+			if err := d.AddSection(".debug_"+name, b); err != nil {
+				return err
+			}
+		}
+	}
+	entries := d.Reader()
+	for {
+		e, err := entries.Next()
+		if err != nil {
+			return err
+		}
+		if e == nil {
+			break
+		}
+		if e.Tag != dwarf.TagCompileUnit {
+			entries.SkipChildren()
+			continue
+		}
+		lr, err := d.LineReader(e)
+		if err != nil || lr == nil {
+			continue
+		}
+		var le dwarf.LineEntry
+		for lr.Next(&le) == nil {
+			if le.File == nil || le.EndSequence {
+				continue
+			}
+			t.lines = append(t.lines, lineEntry{addr: le.Address, file: le.File.Name, line: le.Line})
+		}
+	}
+	sort.Slice(t.lines, func(i, j int) bool {
+		return t.lines[i].addr < t.lines[j].addr
+	})
+	return nil
+}
+
+// Func returns the name of the function with the given index.
+func (t *Table) Func(index int) (string, bool) {
+	name, ok := t.names[index]
+	return name, ok
+}
+
+// Line returns the source location of the instruction at the given byte
+// offset into the module.
+func (t *Table) Line(offset uint64) (string, int, bool) {
+	if offset < t.codeOffset {
+		return "", 0, false
+	}
+	addr := offset - t.codeOffset
+	i := sort.Search(len(t.lines), func(i int) bool {
+		return t.lines[i].addr > addr
+	})
+	if i == 0 {
+		return "", 0, false
+	}
+	l := t.lines[i-1]
+	return l.file, l.line, true
+}
+
+// Symbolize replaces wasm frames in a browser stack trace with Go function
+// names and file.go:line locations where they are known.
+func (t *Table) Symbolize(trace string) string {
+	if t == nil {
+		return trace
+	}
+	return frame.ReplaceAllStringFunc(trace, func(m string) string {
+		sub := frame.FindStringSubmatch(m)
+		index, err := strconv.Atoi(sub[1])
+		if err != nil {
+			return m
+		}
+		offset, err := strconv.ParseUint(sub[2], 16, 64)
+		if err != nil {
+			return m
+		}
+		name, ok := t.Func(index)
+		if !ok {
+			return m
+		}
+		if file, line, ok := t.Line(offset); ok {
+			return fmt.Sprintf("%s (%s:%d)", name, shortPath(file), line)
+		}
+		return name
+	})
+}
+
+// shortPath trims a source path down to its package directory and file.
+func shortPath(file string) string {
+	dir, base := path.Split(filepath.ToSlash(file))
+	dir = strings.TrimSuffix(dir, "/")
+	if dir == "" {
+		return base
+	}
+	return path.Join(path.Base(dir), base)
+}
+
+type reader struct {
+	b   []byte
+	off int
+	err error
+}
+
+func (r *reader) done() bool {
+	return r.off >= len(r.b)
+}
+
+func (r *reader) byte() byte {
+	if r.off >= len(r.b) {
+		r.err = errors.New("unexpected end of module")
+		return 0
+	}
+	c := r.b[r.off]
+	r.off++
+	return c
+}
+
+func (r *reader) uleb() uint64 {
+	var v uint64
+	for shift := 0; shift < 64; shift += 7 {
+		c := r.byte()
+		if r.err != nil {
+			return 0
+		}
+		v |= uint64(c&0x7f) << shift
+		if c&0x80 == 0 {
+			return v
+		}
+	}
+	r.err = errors.New("malformed leb128")
+	return 0
+}
+
+// size reads the length of what follows, which must fit in the rest of
+// the input.
+func (r *reader) size() int {
+	v := r.uleb()
+	if r.err != nil {
+		return 0
+	}
+	if v > uint64(len(r.b)-r.off) {
+		r.err = errors.New("size exceeds the module")
+		return 0
+	}
+	return int(v)
+}
+
+func (r *reader) name() string {
+	n := r.size()
+	if r.err != nil {
+		r.err = errors.New("malformed name")
+		return ""
+	}
+	s := string(r.b[r.off : r.off+n])
+	r.off += n
+	return s
+}
@@ -0,0 +1,191 @@
+package symbols
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func uleb(v int) []byte {
+	var b []byte
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if v != 0 {
+			c |= 0x80
+		}
+		b = append(b, c)
+		if v == 0 {
+			return b
+		}
+	}
+}
+
+func section(id byte, body ...[]byte) []byte {
+	var content []byte
+	for _, b := range body {
+		content = append(content, b...)
+	}
+	return append(append([]byte{id}, uleb(len(content))...), content...)
+}
+
+func name(s string) []byte {
+	return append(uleb(len(s)), s...)
+}
+
+// module returns a wasm module made of the header and sections.
+func module(sections ...[]byte) []byte {
+	b := append([]byte{}, wasmMagic...)
+	b = append(b, 1, 0, 0, 0)
+	for _, s := range sections {
+		b = append(b, s...)
+	}
+	return b
+}
+
+// nameSection names the functions with the given indices.
+func nameSection(names map[int]string, order ...int) []byte {
+	funcs := uleb(len(order))
+	for _, i := range order {
+		funcs = append(append(funcs, uleb(i)...), name(names[i])...)
+	}
+	sub := append(append([]byte{nameFunctions}, uleb(len(funcs))...), funcs...)
+	return section(sectionCustom, name("name"), sub)
+}
+
+func load(t *testing.T, b []byte) (*Table, error) {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "main.wasm")
+	if err := os.WriteFile(file, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return Load(file)
+}
+
+func TestLoad(t *testing.T) {
+	names := nameSection(map[int]string{0: "main.main", 7: "main.App"}, 0, 7)
+	code := section(sectionCode, []byte{0, 0, 0})
+	tests := []struct {
+		name    string
+		b       []byte
+		wantErr string
+		funcs   map[int]string
+	}{
+		{
+			name:  "name section",
+			b:     module(section(1, []byte{0}), code, names),
+			funcs: map[int]string{0: "main.main", 7: "main.App"},
+		},
+		{
+			name:  "other custom sections",
+			b:     module(section(sectionCustom, name("producers"), []byte{1, 2, 3}), names),
+			funcs: map[int]string{7: "main.App"},
+		},
+		{
+			name:    "not wasm",
+			b:       []byte("\x7fELF\x02\x01\x01\x00"),
+			wantErr: "not a wasm module",
+		},
+		{
+			name:    "truncated section",
+			b:       module(names)[:len(module(names))-3],
+			wantErr: "malformed section",
+		},
+		{
+			name:    "unterminated leb",
+			b:       module([]byte{sectionCode, 0x80, 0x80}),
+			wantErr: "malformed section",
+		},
+		{
+			name:    "leb too long",
+			b:       module([]byte{sectionCode, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}),
+			wantErr: "malformed section",
+		},
+		{
+			// a 10 byte leb that would be negative as an int
+			name:    "huge size",
+			b:       module([]byte{sectionCode, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 0}),
+			wantErr: "malformed section",
+		},
+		{
+			name:    "huge custom section name",
+			b:       module(section(sectionCustom, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})),
+			wantErr: "malformed custom section",
+		},
+		{
+			// a broken name subsection loses names, not the module
+			name: "huge function name",
+			b: module(section(sectionCustom, name("name"),
+				[]byte{nameFunctions, 12, 1, 3, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})),
+			funcs: map[int]string{},
+		},
+	}
+	for _, tt := range tests {
+		table, err := load(t, tt.b)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: Load() error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Load() error = %v", tt.name, err)
+			continue
+		}
+		for i, want := range tt.funcs {
+			if got, ok := table.Func(i); !ok || got != want {
+				t.Errorf("%s: Func(%d) = %q, %v, want %q", tt.name, i, got, ok, want)
+			}
+		}
+	}
+}
+
+func TestLine(t *testing.T) {
+	table := &Table{
+		codeOffset: 0x100,
+		lines: []lineEntry{
+			{addr: 0x10, file: "/src/main.go", line: 5},
+			{addr: 0x20, file: "/src/main.go", line: 6},
+			{addr: 0x40, file: "/goui/goui.go", line: 90},
+		},
+	}
+	tests := []struct {
+		offset uint64
+		file   string
+		line   int
+		ok     bool
+	}{
+		{0x50, "", 0, false},
+		{0x10f, "", 0, false},
+		{0x110, "/src/main.go", 5, true},
+		{0x11f, "/src/main.go", 5, true},
+		{0x120, "/src/main.go", 6, true},
+		{0x13f, "/src/main.go", 6, true},
+		{0x140, "/goui/goui.go", 90, true},
+		{0x9999, "/goui/goui.go", 90, true},
+	}
+	for _, tt := range tests {
+		file, line, ok := table.Line(tt.offset)
+		if file != tt.file || line != tt.line || ok != tt.ok {
+			t.Errorf("Line(%#x) = %q, %d, %v, want %q, %d, %v", tt.offset, file, line, ok, tt.file, tt.line, tt.ok)
+		}
+	}
+}
+
+func TestSymbolize(t *testing.T) {
+	table := &Table{
+		names:      map[int]string{3: "main.App"},
+		codeOffset: 0x100,
+		lines:      []lineEntry{{addr: 0x10, file: "/home/me/app/src/main.go", line: 12}},
+	}
+	trace := "at wasm-function[3]:0x115\nat wasm-function[4]:0x120\nat wasm-function[3]:0x10"
+	want := "at main.App (src/main.go:12)\nat wasm-function[4]:0x120\nat main.App"
+	if got := table.Symbolize(trace); got != want {
+		t.Errorf("Symbolize() =\n%s\nwant\n%s", got, want)
+	}
+	var empty *Table
+	if got := empty.Symbolize(trace); got != trace {
+		t.Errorf("nil Table changed the trace: %s", got)
+	}
+}
@@ -0,0 +1,98 @@
+package upgrade
+
+import (
+	"strings"
+	"testing"
+)
+
+func lines(n int, prefix string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		b.WriteString(prefix)
+		b.WriteByte(byte('a' + i - 1))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "equal",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+			want: "",
+		},
+		{
+			name: "both empty",
+			want: "",
+		},
+		{
+			name: "empty a",
+			b:    "a\nb\n",
+			want: "--- f\n+++ f (new)\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "empty b",
+			a:    "a\nb\n",
+			want: "--- f\n+++ f (new)\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name: "pure insert",
+			a:    "a\nb\nc\nd\ne\nf\n",
+			b:    "a\nb\nc\nX\nd\ne\nf\n",
+			want: "--- f\n+++ f (new)\n@@ -1,6 +1,7 @@\n a\n b\n c\n+X\n d\n e\n f\n",
+		},
+		{
+			name: "pure delete",
+			a:    "a\nb\nc\nX\nd\ne\nf\n",
+			b:    "a\nb\nc\nd\ne\nf\n",
+			want: "--- f\n+++ f (new)\n@@ -1,7 +1,6 @@\n a\n b\n c\n-X\n d\n e\n f\n",
+		},
+		{
+			name: "insert at the end",
+			a:    "a\nb\nc\nd\ne\n",
+			b:    "a\nb\nc\nd\ne\nX\n",
+			want: "--- f\n+++ f (new)\n@@ -3,3 +3,4 @@\n c\n d\n e\n+X\n",
+		},
+		{
+			name: "replace",
+			a:    "a\nb\nc\n",
+			b:    "a\nX\nc\n",
+			want: "--- f\n+++ f (new)\n@@ -1,3 +1,3 @@\n a\n-b\n+X\n c\n",
+		},
+		{
+			// 7 unchanged lines between the changes is more than twice the
+			// context, so they get a hunk each
+			name: "changes far apart",
+			a:    "X\n" + lines(7, "") + "Y\n",
+			b:    "x\n" + lines(7, "") + "y\n",
+			want: "--- f\n+++ f (new)\n" +
+				"@@ -1,4 +1,4 @@\n-X\n+x\n a\n b\n c\n" +
+				"@@ -6,4 +6,4 @@\n e\n f\n g\n-Y\n+y\n",
+		},
+		{
+			// 6 unchanged lines fit in the context of both changes
+			name: "changes close together",
+			a:    "X\n" + lines(6, "") + "Y\n",
+			b:    "x\n" + lines(6, "") + "y\n",
+			want: "--- f\n+++ f (new)\n" +
+				"@@ -1,8 +1,8 @@\n-X\n+x\n a\n b\n c\n d\n e\n f\n-Y\n+y\n",
+		},
+		{
+			name: "missing final newline",
+			a:    "a\nb",
+			b:    "a\nc\n",
+			want: "--- f\n+++ f (new)\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n",
+		},
+	}
+	for _, tt := range tests {
+		if got := diff("f", []byte(tt.a), []byte(tt.b)); got != tt.want {
+			t.Errorf("%s: diff() =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package upgrade
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/goui-org/gouix/config"
+	"github.com/goui-org/gouix/create"
+	"github.com/goui-org/gouix/editor"
+	"github.com/goui-org/gouix/files"
+	"github.com/goui-org/gouix/utils"
+	"golang.org/x/mod/semver"
+)
+
+const gouiModule = "github.com/goui-org/goui"
+
+// scaffold is a file written by gouix create.
+type scaffold struct {
+	path    string
+	current []byte
+	// released holds the sha256 sums of earlier versions of the file. Add
+	// the sum of the old version whenever the embedded file changes.
+	released []string
+	// merge marks JSON files that get the keys of the current version
+	// merged in when they were modified, instead of a diff
+	merge bool
+}
+
+var scaffolds = []*scaffold{
+	{
+		path:     "public/index.html",
+		current:  files.IndexHTML,
+		released: []string{"8879aebe456037373ad8494c3cd7f8e5d17d86e2265e0af57d584c932839b4e0"},
+	},
+	{
+		path:     ".vscode/settings.json",
+		current:  editor.VSCodeSettings(),
+		released: []string{"8d14cc94a62370849d2d878d8ded22bcb7781f72d63bfdaceae4cdcb30f2b5de"},
+		merge:    true,
+	},
+	{
+		path:     ".gitignore",
+		current:  files.GitIgnore,
+		released: []string{"f10ec3ffef16a8dace9b58801e0da28819c98af4c8933410d7e7cacc952f9e9b"},
+	},
+	{
+		path:     "README.md",
+		current:  files.ReadmeMD,
+		released: []string{"c9b344ef895df55af622f021bd7cdc6f79e543e055f7ad63f1c6272f8b431ea8"},
+	},
+}
+
+// gouiYML holds the sums of released goui.yml templates. An unmodified
+// goui.yml is replaced by the current template instead of migrated.
+var gouiYML = &scaffold{
+	path:    "goui.yml",
+	current: files.GoUIYML,
+	released: []string{
+		"7d76e6eb2682b388292af4e9df737ebe515928679b004a69008bbea658322aeb",
+		"8f18561f19d7e01ae5e49beba952038dfa0e0eecb2afe902820858cea07ab0a0",
+		"a668565c6ebe1ccf8c6c92a4a5e96ca615a2589bfb1c05d294cac40e91397fc9",
+	},
+}
+
+// Options configure Upgrade.
+type Options struct {
+	// GouiVersion is the goui version to require, "latest" or vX.Y.Z.
+	GouiVersion string
+	// DryRun reports the changes without making them.
+	DryRun bool
+}
+
+// unmodified reports whether b is a version of the file gouix wrote.
+func (s *scaffold) unmodified(b []byte) bool {
+	sum := sha256.Sum256(b)
+	hash := hex.EncodeToString(sum[:])
+	for _, h := range s.released {
+		if h == hash {
+			return true
+		}
+	}
+	return string(b) == string(s.current)
+}
+
+// Upgrade brings the project in the current directory up to date with
+// this gouix: it bumps goui, migrates goui.yml and replaces scaffold files
+// that weren't modified. Modified JSON settings get the current keys merged
+// in; for other modified files a diff is printed.
+func Upgrade(opts *Options) error {
+	fail := func(err error) error {
+		return fmt.Errorf("upgrade.Upgrade: %w", err)
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	var changes []string
+	change := func(format string, a ...any) {
+		changes = append(changes, fmt.Sprintf(format, a...))
+	}
+	write := func(p string, b []byte) error {
+		if opts.DryRun {
+			return nil
+		}
+		if err := utils.Mkdir(filepath.Dir(filepath.FromSlash(p))); err != nil {
+			return err
+		}
+		return utils.WriteFile(filepath.FromSlash(p), b)
+	}
+
+	b, err := os.ReadFile("goui.yml")
+	if err != nil {
+		return fail(err)
+	}
+	yml := b
+	if gouiYML.unmodified(b) {
+		if string(b) != string(gouiYML.current) {
+			yml = gouiYML.current
+			if err := write(gouiYML.path, yml); err != nil {
+				return fail(err)
+			}
+			change("goui.yml: replaced with the current template")
+		}
+	} else {
+		migrated, applied, err := config.Migrate(b)
+		if err != nil {
+			return fail(err)
+		}
+		if len(applied) > 0 {
+			yml = migrated
+			if err := write(gouiYML.path, yml); err != nil {
+				return fail(err)
+			}
+			for _, a := range applied {
+				change("goui.yml: %s", a)
+			}
+		}
+	}
+
+	// the schema is generated, so it's replaced whenever it's used
+	schema := config.JSONSchemaBytes()
+	old, err := os.ReadFile(filepath.FromSlash(editor.SchemaPath))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fail(err)
+	}
+	if (err == nil || strings.Contains(string(yml), editor.SchemaPath)) && string(old) != string(schema) {
+		if err := write(editor.SchemaPath, schema); err != nil {
+			return fail(err)
+		}
+		change("%s: updated", editor.SchemaPath)
+	}
+
+	var diffs []string
+	for _, s := range scaffolds {
+		b, err := os.ReadFile(filepath.FromSlash(s.path))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fail(err)
+		}
+		switch {
+		case string(b) == string(s.current):
+		case s.unmodified(b):
+			if err := write(s.path, s.current); err != nil {
+				return fail(err)
+			}
+			change("%s: updated", s.path)
+		case s.merge:
+			merged, err := editor.MergeJSON(filepath.FromSlash(s.path), s.current)
+			if errors.Is(err, editor.ErrNotJSON) {
+				diffs = append(diffs, diff(s.path, b, s.current))
+				continue
+			}
+			if err != nil {
+				return fail(err)
+			}
+			if merged == nil {
+				continue
+			}
+			if err := write(s.path, merged); err != nil {
+				return fail(err)
+			}
+			change("%s: merged in the current settings", s.path)
+		default:
+			diffs = append(diffs, diff(s.path, b, s.current))
+		}
+	}
+
+	have, err := requiredVersion()
+	if err != nil {
+		return fail(err)
+	}
+	want, err := create.ResolveGouiVersion(opts.GouiVersion)
+	if err != nil {
+		return fail(err)
+	}
+	// only go back to an older version when asked for it explicitly
+	if want != have && (opts.GouiVersion != "" && opts.GouiVersion != "latest" || newer(want, have)) {
+		if !opts.DryRun {
+			if err := utils.Command("go", "get", gouiModule+"@"+want); err != nil {
+				return fail(err)
+			}
+		}
+		change("goui: %s -> %s", have, want)
+	}
+
+	report(changes, diffs, opts.DryRun)
+	return nil
+}
+
+func report(changes []string, diffs []string, dryRun bool) {
+	verb := "changed"
+	if dryRun {
+		verb = "would change"
+	}
+	if len(changes) == 0 {
+		color.Green("nothing to upgrade\n")
+	} else {
+		fmt.Printf("upgrade %s:\n\n", verb)
+		for _, c := range changes {
+			color.Green("\t%s\n", c)
+		}
+	}
+	if len(diffs) == 0 {
+		return
+	}
+	fmt.Printf("\nthese files were modified, so they were left alone. The current templates differ like this:\n\n")
+	for _, d := range diffs {
+		for _, line := range strings.Split(strings.TrimSuffix(d, "\n"), "\n") {
+			c := color.New(color.Reset)
+			switch {
+			case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+				c = color.New(color.Bold)
+			case strings.HasPrefix(line, "+"):
+				c = color.New(color.FgGreen)
+			case strings.HasPrefix(line, "-"):
+				c = color.New(color.FgRed)
+			case strings.HasPrefix(line, "@@"):
+				c = color.New(color.FgCyan)
+			}
+			c.Println(line)
+		}
+		fmt.Println()
+	}
+}
+
+type goModJSON struct {
+	Require []struct {
+		Path    string
+		Version string
+	}
+}
+
+// requiredVersion returns the version of goui in go.mod.
+func requiredVersion() (string, error) {
+	out, err := exec.Command("go", "mod", "edit", "-json").Output()
+	if err != nil {
+		return "", err
+	}
+	var mod goModJSON
+	if err := json.Unmarshal(out, &mod); err != nil {
+		return "", err
+	}
+	for _, r := range mod.Require {
+		if r.Path == gouiModule {
+			return r.Version, nil
+		}
+	}
+	return "", fmt.Errorf("go.mod doesn't require %s", gouiModule)
+}
+
+// newer reports whether version a is newer than b in semver order, where
+// a release comes after its pre-releases.
+func newer(a, b string) bool {
+	return semver.Compare(a, b) > 0
+}
@@ -0,0 +1,37 @@
+package upgrade
+
+import "testing"
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"v0.2.9", "v0.2.8", true},
+		{"v0.2.8", "v0.2.9", false},
+		{"v0.2.8", "v0.2.8", false},
+		{"v0.10.0", "v0.9.9", true},
+		{"v1.0.0", "v0.99.99", true},
+		{"v0.3", "v0.2.8", true},
+		{"v0.3.0-rc.1", "v0.2.8", true},
+		{"v0.2.8", "v0.3.0-rc.1", false},
+		// a release comes after its pre-releases
+		{"v0.3.0", "v0.3.0-rc.1", true},
+		{"v0.3.0-rc.1", "v0.3.0", false},
+		{"v0.3.0-rc.2", "v0.3.0-rc.1", true},
+		{"v0.3.0-rc.10", "v0.3.0-rc.9", true},
+		{"v0.3.0-rc.1", "v0.3.0-rc.1", false},
+		{"v0.3.0-beta", "v0.3.0-alpha.2", true},
+		{"v0.3.0-rc.1.1", "v0.3.0-rc.1", true},
+		{"v0.3.0-rc", "v0.3.0-1", true},
+		// pseudo-versions are pre-releases of the next patch
+		{"v0.2.9-0.20240101000000-abcdef123456", "v0.2.8", true},
+		{"v0.2.9", "v0.2.9-0.20240101000000-abcdef123456", true},
+		{"v0.2.9-0.20240201000000-abcdef123456", "v0.2.9-0.20240101000000-fedcba654321", true},
+	}
+	for _, tt := range tests {
+		if got := newer(tt.a, tt.b); got != tt.want {
+			t.Errorf("newer(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -3,8 +3,12 @@ package utils
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"mime"
+	"net"
 	"os"
 	"os/exec"
 	"path"
@@ -25,7 +29,7 @@ func Mkdir(dirs ...string) error {
 }
 
 func WriteFile(path string, data []byte) error {
-	return os.WriteFile(path, data, 0755)
+	return os.WriteFile(path, data, 0644)
 }
 
 func GzipSize(path string) (int64, error) {
@@ -102,14 +106,80 @@ func CopyFile(src string, dst string, m *minify.M) error {
 			if err := m.Minify(ty, out, bytes.NewBuffer(data)); err != nil {
 				return err
 			}
-			return os.WriteFile(dst, out.Bytes(), 0755)
+			return os.WriteFile(dst, out.Bytes(), 0644)
 		}
 	}
-	return os.WriteFile(dst, data, 0755)
+	return os.WriteFile(dst, data, 0644)
+}
+
+// LANAddresses returns the IPv4 addresses of this machine on the local
+// network.
+func LANAddresses() []string {
+	var addrs []string
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		ifaddrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range ifaddrs {
+			ipnet, ok := addr.(*net.IPNet)
+			if !ok || ipnet.IP.To4() == nil || ipnet.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			addrs = append(addrs, ipnet.IP.String())
+		}
+	}
+	return addrs
+}
+
+type goModJSON struct {
+	Module struct {
+		Path string
+	}
+}
+
+// ModulePath returns the path of the Go module in the current directory.
+func ModulePath() (string, error) {
+	fail := func(err error) (string, error) {
+		return "", fmt.Errorf("utils.ModulePath: %w", err)
+	}
+	if _, err := os.Stat("go.mod"); err != nil {
+		return fail(errors.New("no go.mod in the current directory, run go mod init first"))
+	}
+	out, err := exec.Command("go", "mod", "edit", "-json").Output()
+	if err != nil {
+		return fail(err)
+	}
+	var mod goModJSON
+	if err := json.Unmarshal(out, &mod); err != nil {
+		return fail(err)
+	}
+	return mod.Module.Path, nil
 }
 
 func Command(name string, args ...string) error {
-	cmd := exec.Command(name, args...)
+	return CommandContext(context.Background(), name, args...)
+}
+
+// CommandInDir is like Command but runs the process in dir.
+func CommandInDir(dir string, name string, args ...string) error {
+	return run(exec.Command(name, args...), dir)
+}
+
+// CommandContext is like Command but kills the process when ctx is done.
+func CommandContext(ctx context.Context, name string, args ...string) error {
+	return run(exec.CommandContext(ctx, name, args...), "")
+}
+
+func run(cmd *exec.Cmd, dir string) error {
+	cmd.Dir = dir
 	var out bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &out